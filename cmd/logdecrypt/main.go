@@ -0,0 +1,189 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 18:30
+ * @FilePath: log//cmd/logdecrypt/main.go
+ */
+
+// Command logdecrypt decrypts the log files encrypted by github.com/restoflife/log.
+//
+//	logdecrypt -keys keyring.txt -backups gin.log > gin.plain.log
+//	logdecrypt -keys keyring.txt -f gin.log | logq -where 'Code>=500' /dev/stdin
+//
+// The keyring has one "id key" or "id=key" per line with base64 or hex encoded
+// AES keys. With -f the file is followed as it grows and across rotations.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/restoflife/log/internal/cli"
+	"github.com/restoflife/log/logfile"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+func main() {
+	var (
+		keyring string
+		keys    cli.MultiFlag
+		follow  bool
+		backups bool
+		poll    time.Duration
+	)
+	flag.StringVar(&keyring, "keys", "", "keyring file, one \"id key\" per line")
+	flag.Var(&keys, "key", "key as id=base64 (repeatable)")
+	flag.BoolVar(&follow, "f", false, "follow the file as it grows and across rotations")
+	flag.BoolVar(&backups, "backups", false, "also decrypt the rotated backups of each file, oldest first")
+	flag.DurationVar(&poll, "poll", 250*time.Millisecond, "poll interval when following")
+	flag.Usage = func() {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "usage: logdecrypt [flags] file...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 || (follow && flag.NArg() != 1) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ring, err := loadKeys(keyring, keys)
+	if err != nil {
+		cli.Fatal(err)
+	}
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	var files []string
+	for _, name := range flag.Args() {
+		if !backups {
+			files = append(files, name)
+			continue
+		}
+		names, err := logfile.Backups(name)
+		if err != nil {
+			cli.Fatal(err)
+		}
+		files = append(files, names...)
+		files = append(files, name)
+	}
+	if follow {
+		// Decrypt what is there, then tail the file
+		for _, name := range files[:len(files)-1] {
+			if err := decrypt(out, name, ring); err != nil {
+				cli.Fatal(err)
+			}
+		}
+		if err := tail(out, files[len(files)-1], ring, poll); err != nil {
+			cli.Fatal(err)
+		}
+		return
+	}
+	for _, name := range files {
+		if err := decrypt(out, name, ring); err != nil {
+			cli.Fatal(err)
+		}
+	}
+}
+
+// loadKeys reads the keyring file and adds the keys given on the command line
+func loadKeys(keyring string, keys []string) (logfile.Keyring, error) {
+	ring := make(logfile.Keyring)
+	if keyring != "" {
+		var err error
+		if ring, err = logfile.ReadKeyring(keyring); err != nil {
+			return nil, err
+		}
+	}
+	more, err := logfile.ParseKeyring(strings.NewReader(strings.Join(keys, "\n")))
+	if err != nil {
+		return nil, err
+	}
+	for id, key := range more {
+		ring[id] = key
+	}
+	return ring, nil
+}
+
+// decrypt writes the plain text of the file
+func decrypt(w io.Writer, name string, keys logfile.Keyring) error {
+	f, err := logfile.OpenWithKeys(name, keys)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err = io.Copy(w, f); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// errRotated is returned by follower when the file has been rotated
+var errRotated = errors.New("file rotated")
+
+// tail writes the plain text of the file as it grows, reopening it when lumberjack rotates it
+func tail(w *bufio.Writer, name string, keys logfile.Keyring, poll time.Duration) error {
+	for {
+		f, err := os.Open(name)
+		if err != nil {
+			// The new file is created on the first write after the rotation
+			if os.IsNotExist(err) {
+				time.Sleep(poll)
+				continue
+			}
+			return err
+		}
+		fw := &follower{f: f, name: name, poll: poll, flush: w.Flush}
+		br := bufio.NewReader(fw)
+		var r io.Reader = br
+		if head, err := br.Peek(len(logfile.Magic)); err == nil && logfile.IsEncrypted(head) {
+			r = logfile.NewDecryptReader(br, keys)
+		} else if errors.Is(err, errRotated) {
+			_ = f.Close()
+			continue
+		}
+		_, err = io.Copy(w, r)
+		_ = f.Close()
+		if !errors.Is(err, errRotated) {
+			return err
+		}
+	}
+}
+
+// follower reads a growing file, waiting at the end of the file until data is written or the file is rotated
+type follower struct {
+	f     *os.File
+	name  string
+	poll  time.Duration
+	flush func() error
+}
+
+func (fw *follower) Read(p []byte) (int, error) {
+	for {
+		n, err := fw.f.Read(p)
+		if n > 0 || (err != nil && !errors.Is(err, io.EOF)) {
+			return n, err
+		}
+		_ = fw.flush()
+		if fw.rotated() {
+			return 0, errRotated
+		}
+		time.Sleep(fw.poll)
+	}
+}
+
+// rotated reports whether the name now refers to another file
+func (fw *follower) rotated() bool {
+	cur, err := fw.f.Stat()
+	if err != nil {
+		return true
+	}
+	info, err := os.Stat(fw.name)
+	if err != nil {
+		return os.IsNotExist(err)
+	}
+	return !os.SameFile(cur, info)
+}
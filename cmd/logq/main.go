@@ -0,0 +1,264 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 15:30
+ * @FilePath: log//cmd/logq/main.go
+ */
+
+// Command logq queries the files written by github.com/restoflife/log.
+//
+//	logq -level warn -prefix gin -where 'Code>=500' -where 'Latency>1s' -o csv -fields Path,Code gin.log
+//
+// Rotated backups, gzipped or not, are read before the file with -backups.
+// Encrypted files are decrypted with the keyring given by -keys.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/restoflife/log/internal/cli"
+	"github.com/restoflife/log/logfile"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+func main() {
+	var (
+		since, until, level, output, fields string
+		prefixes, where                     cli.MultiFlag
+		backups                             bool
+		keyring                             string
+	)
+	flag.StringVar(&since, "since", "", "only entries at or after this time, RFC3339 or a duration ago such as 1h")
+	flag.StringVar(&until, "until", "", "only entries before this time, RFC3339 or a duration ago such as 10m")
+	flag.StringVar(&level, "level", "", "minimum level: debug, info, warn, error...")
+	flag.Var(&prefixes, "prefix", "message prefix such as gin, sql, gorm or elastic (repeatable)")
+	flag.Var(&where, "where", "field predicate such as Code>=500, Latency>1s or Path~/api (repeatable)")
+	flag.StringVar(&output, "o", "text", "output format: text, json or csv")
+	flag.StringVar(&fields, "fields", "", "comma separated fields of the csv output, all fields by default")
+	flag.StringVar(&keyring, "keys", "", "keyring file used to decrypt encrypted files, one \"id key\" per line")
+	flag.BoolVar(&backups, "backups", false, "also read the rotated backups of each file, oldest first")
+	flag.Usage = func() {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "usage: logq [flags] file...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	filter := logfile.Filter{Level: level, Prefixes: prefixes}
+	var err error
+	if filter.Since, err = cli.ParseTime(since); err != nil {
+		cli.Fatal(err)
+	}
+	if filter.Until, err = cli.ParseTime(until); err != nil {
+		cli.Fatal(err)
+	}
+	for _, expr := range where {
+		p, err := logfile.ParsePredicate(expr)
+		if err != nil {
+			cli.Fatal(err)
+		}
+		filter.Predicates = append(filter.Predicates, p)
+	}
+
+	var keys logfile.Keyring
+	if keyring != "" {
+		if keys, err = logfile.ReadKeyring(keyring); err != nil {
+			cli.Fatal(err)
+		}
+	}
+
+	var files []string
+	for _, name := range flag.Args() {
+		if !backups {
+			files = append(files, name)
+			continue
+		}
+		names, err := logfile.WithBackups(name)
+		if err != nil {
+			cli.Fatal(err)
+		}
+		files = append(files, names...)
+	}
+
+	out, err := newWriter(output, os.Stdout, fields)
+	if err != nil {
+		cli.Fatal(err)
+	}
+	for _, name := range files {
+		if err := query(name, keys, filter, out); err != nil {
+			cli.Fatal(err)
+		}
+	}
+	if err := out.Flush(); err != nil {
+		cli.Fatal(err)
+	}
+}
+
+// query writes the entries of the file matching the filter
+func query(name string, keys logfile.Keyring, filter logfile.Filter, out writer) error {
+	f, err := logfile.OpenWithKeys(name, keys)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := logfile.NewReader(f)
+	for {
+		e, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if filter.Match(e) {
+			if err := out.Write(e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writer writes the matching entries in an output format
+type writer interface {
+	Write(e logfile.Entry) error
+	Flush() error
+}
+
+// newWriter Create the writer of the output format
+func newWriter(format string, w io.Writer, fields string) (writer, error) {
+	switch format {
+	case "text":
+		return &textWriter{w: w}, nil
+	case "json":
+		return &jsonWriter{enc: json.NewEncoder(w)}, nil
+	case "csv":
+		cw := &csvWriter{w: csv.NewWriter(w)}
+		if fields != "" {
+			cw.fields = strings.Split(fields, ",")
+		}
+		return cw, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q", format)
+}
+
+// textWriter writes the entries as they appear in the file
+type textWriter struct {
+	w io.Writer
+}
+
+func (t *textWriter) Write(e logfile.Entry) error {
+	_, err := fmt.Fprintln(t.w, e.Raw)
+	return err
+}
+
+func (t *textWriter) Flush() error {
+	return nil
+}
+
+// jsonWriter writes one JSON object per entry
+type jsonWriter struct {
+	enc *json.Encoder
+}
+
+func (j *jsonWriter) Write(e logfile.Entry) error {
+	return j.enc.Encode(toMap(e))
+}
+
+func (j *jsonWriter) Flush() error {
+	return nil
+}
+
+// toMap flattens the columns and the fields of an entry, the columns win over fields with the same name
+func toMap(e logfile.Entry) map[string]interface{} {
+	m := make(map[string]interface{}, len(e.Fields)+6)
+	for k, v := range e.Fields {
+		m[k] = v
+	}
+	m["time"] = e.Time.Format(time.RFC3339Nano)
+	m["level"] = e.Level
+	m["msg"] = e.Message
+	if e.Logger != "" {
+		m["logger"] = e.Logger
+	}
+	if e.Caller != "" {
+		m["caller"] = e.Caller
+	}
+	if e.Stack != "" {
+		m["stacktrace"] = e.Stack
+	}
+	return m
+}
+
+// csvWriter writes the columns and the selected fields as CSV
+type csvWriter struct {
+	w      *csv.Writer
+	fields []string
+	// rows are buffered when the fields are not selected, the header needs every field name
+	rows []logfile.Entry
+	// header is set once the header has been written
+	header bool
+}
+
+// csvColumns are the leading columns of the csv output
+var csvColumns = []string{"time", "level", "logger", "caller", "msg"}
+
+func (c *csvWriter) Write(e logfile.Entry) error {
+	if c.fields == nil {
+		c.rows = append(c.rows, e)
+		return nil
+	}
+	return c.writeRow(e)
+}
+
+func (c *csvWriter) writeRow(e logfile.Entry) error {
+	if !c.header {
+		c.header = true
+		if err := c.w.Write(append(append([]string{}, csvColumns...), c.fields...)); err != nil {
+			return err
+		}
+	}
+	row := make([]string, 0, len(csvColumns)+len(c.fields))
+	for _, name := range csvColumns {
+		v, _ := e.Field(name)
+		row = append(row, logfile.ValueString(v))
+	}
+	for _, name := range c.fields {
+		v, _ := e.Field(name)
+		row = append(row, logfile.ValueString(v))
+	}
+	return c.w.Write(row)
+}
+
+func (c *csvWriter) Flush() error {
+	if c.fields == nil {
+		// Every field name seen becomes a column
+		seen := make(map[string]struct{})
+		c.fields = []string{}
+		for _, e := range c.rows {
+			for k := range e.Fields {
+				if _, ok := seen[k]; !ok {
+					seen[k] = struct{}{}
+					c.fields = append(c.fields, k)
+				}
+			}
+		}
+		sort.Strings(c.fields)
+		for _, e := range c.rows {
+			if err := c.writeRow(e); err != nil {
+				return err
+			}
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,119 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 16:05
+ * @FilePath: log//cmd/logreport/main.go
+ */
+
+// Command logreport summarises the gin access log and the SQL log written by
+// github.com/restoflife/log: request counts, latency percentiles per route,
+// status codes, and the slowest and most frequent SQL statements.
+//
+//	logreport -backups -top 20 gin.log sql.log
+//	logreport -o html gin.log sql.log > report.html
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/restoflife/log/internal/cli"
+	"github.com/restoflife/log/logfile"
+	"io"
+	"os"
+)
+
+func main() {
+	var (
+		since, until, output string
+		top                  int
+		backups              bool
+		keyring              string
+	)
+	flag.StringVar(&since, "since", "", "only entries at or after this time, RFC3339 or a duration ago such as 24h")
+	flag.StringVar(&until, "until", "", "only entries before this time, RFC3339 or a duration ago")
+	flag.StringVar(&output, "o", "text", "output format: text or html")
+	flag.IntVar(&top, "top", 10, "number of routes and SQL statements listed")
+	flag.StringVar(&keyring, "keys", "", "keyring file used to decrypt encrypted files, one \"id key\" per line")
+	flag.BoolVar(&backups, "backups", false, "also read the rotated backups of each file, oldest first")
+	flag.Usage = func() {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "usage: logreport [flags] gin.log sql.log...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if top <= 0 {
+		top = 10
+	}
+
+	var (
+		filter logfile.Filter
+		err    error
+	)
+	if filter.Since, err = cli.ParseTime(since); err != nil {
+		cli.Fatal(err)
+	}
+	if filter.Until, err = cli.ParseTime(until); err != nil {
+		cli.Fatal(err)
+	}
+
+	var keys logfile.Keyring
+	if keyring != "" {
+		if keys, err = logfile.ReadKeyring(keyring); err != nil {
+			cli.Fatal(err)
+		}
+	}
+
+	b := newBuilder(top)
+	for _, name := range flag.Args() {
+		files := []string{name}
+		if backups {
+			if files, err = logfile.WithBackups(name); err != nil {
+				cli.Fatal(err)
+			}
+		}
+		for _, fn := range files {
+			if err := read(fn, keys, filter, b); err != nil {
+				cli.Fatal(err)
+			}
+		}
+	}
+	report := b.build()
+
+	switch output {
+	case "text":
+		err = writeText(os.Stdout, report, top)
+	case "html":
+		err = writeHTML(os.Stdout, report, top)
+	default:
+		err = fmt.Errorf("unknown output format %q", output)
+	}
+	if err != nil {
+		cli.Fatal(err)
+	}
+}
+
+// read adds the entries of the file matching the filter to the report
+func read(name string, keys logfile.Keyring, filter logfile.Filter, b *builder) error {
+	f, err := logfile.OpenWithKeys(name, keys)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := logfile.NewReader(f)
+	for {
+		e, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if filter.Match(e) {
+			b.add(e)
+		}
+	}
+}
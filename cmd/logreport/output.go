@@ -0,0 +1,158 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 16:50
+ * @FilePath: log//cmd/logreport/output.go
+ */
+
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// writeText writes the report as terminal tables
+func writeText(w io.Writer, r Report, top int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	p := func(format string, args ...interface{}) {
+		_, _ = fmt.Fprintf(tw, format, args...)
+	}
+	p("Period\t%s - %s\n", formatTime(r.From), formatTime(r.To))
+	p("Requests\t%d\n", r.Requests)
+	p("SQL statements\t%d\n\n", r.Statements)
+
+	if r.Requests > 0 {
+		p("CLASS\tCOUNT\tSHARE\n")
+		for _, s := range r.Classes {
+			p("%s\t%d\t%.1f%%\n", s.Status, s.Count, 100*float64(s.Count)/float64(r.Requests))
+		}
+		p("\nSTATUS\tCOUNT\tSHARE\n")
+		for _, s := range r.Statuses {
+			p("%s\t%d\t%.1f%%\n", s.Status, s.Count, 100*float64(s.Count)/float64(r.Requests))
+		}
+		p("\nMETHOD\tPATH\tCOUNT\t5XX\tP50\tP90\tP99\tMAX\n")
+		for i, route := range r.Routes {
+			if i == top {
+				break
+			}
+			p("%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\n", route.Method, route.Path, route.Count, route.Errors,
+				formatDuration(route.P50), formatDuration(route.P90), formatDuration(route.P99), formatDuration(route.Max))
+		}
+		p("\n")
+	}
+
+	if r.Statements > 0 {
+		p("SLOWEST SQL\tSOURCE\tLATENCY\tTIME\n")
+		for _, s := range r.Slowest {
+			p("%s\t%s\t%s\t%s\n", truncate(s.SQL, 100), s.Source, formatDuration(s.Latency), formatTime(s.Time))
+		}
+		p("\nFREQUENT SQL\tCOUNT\tERRORS\tAVG\tMAX\tTOTAL\n")
+		for _, f := range r.Frequent {
+			p("%s\t%d\t%d\t%s\t%s\t%s\n", truncate(f.SQL, 100), f.Count, f.Errors,
+				formatDuration(f.Avg()), formatDuration(f.Max), formatDuration(f.Total))
+		}
+	}
+	return tw.Flush()
+}
+
+// htmlReport renders the report as a standalone HTML page
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"duration": formatDuration,
+	"time":     formatTime,
+	"share": func(n, total int) string {
+		if total == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%.1f%%", 100*float64(n)/float64(total))
+	},
+	"limit": func(routes []*Route, n int) []*Route {
+		if len(routes) > n {
+			return routes[:n]
+		}
+		return routes
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Access and SQL report</title>
+<style>
+body{font-family:sans-serif;font-size:14px;margin:24px;color:#222}
+table{border-collapse:collapse;margin-bottom:24px}
+td,th{border-bottom:1px solid #ddd;padding:4px 10px;text-align:left}
+td.n{text-align:right;font-variant-numeric:tabular-nums}
+code{font-size:12px}
+</style>
+</head>
+<body>
+<h1>Access and SQL report</h1>
+<p>{{time .R.From}} &ndash; {{time .R.To}}: {{.R.Requests}} requests, {{.R.Statements}} SQL statements</p>
+{{if .R.Requests}}
+<h2>Status classes</h2>
+<table>
+<tr><th>Class</th><th>Count</th><th>Share</th></tr>
+{{range .R.Classes}}<tr><td>{{.Status}}</td><td class="n">{{.Count}}</td><td class="n">{{share .Count $.R.Requests}}</td></tr>
+{{end}}</table>
+<h2>Status codes</h2>
+<table>
+<tr><th>Status</th><th>Count</th><th>Share</th></tr>
+{{range .R.Statuses}}<tr><td>{{.Status}}</td><td class="n">{{.Count}}</td><td class="n">{{share .Count $.R.Requests}}</td></tr>
+{{end}}</table>
+<h2>Routes</h2>
+<table>
+<tr><th>Method</th><th>Path</th><th>Count</th><th>5xx</th><th>p50</th><th>p90</th><th>p99</th><th>Max</th></tr>
+{{range limit .R.Routes .Top}}<tr><td>{{.Method}}</td><td>{{.Path}}</td><td class="n">{{.Count}}</td><td class="n">{{.Errors}}</td><td class="n">{{duration .P50}}</td><td class="n">{{duration .P90}}</td><td class="n">{{duration .P99}}</td><td class="n">{{duration .Max}}</td></tr>
+{{end}}</table>
+{{end}}
+{{if .R.Statements}}
+<h2>Slowest SQL</h2>
+<table>
+<tr><th>SQL</th><th>Source</th><th>Latency</th><th>Time</th></tr>
+{{range .R.Slowest}}<tr><td><code>{{.SQL}}</code></td><td>{{.Source}}</td><td class="n">{{duration .Latency}}</td><td>{{time .Time}}</td></tr>
+{{end}}</table>
+<h2>Most frequent SQL</h2>
+<table>
+<tr><th>Fingerprint</th><th>Count</th><th>Errors</th><th>Avg</th><th>Max</th><th>Total</th></tr>
+{{range .R.Frequent}}<tr><td><code>{{.SQL}}</code></td><td class="n">{{.Count}}</td><td class="n">{{.Errors}}</td><td class="n">{{duration .Avg}}</td><td class="n">{{duration .Max}}</td><td class="n">{{duration .Total}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+// writeHTML writes the report as a standalone HTML page
+func writeHTML(w io.Writer, r Report, top int) error {
+	return htmlReport.Execute(w, map[string]interface{}{"R": r, "Top": top})
+}
+
+// formatDuration rounds a duration for display
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	}
+	return d.String()
+}
+
+// formatTime formats a time for display
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// truncate shortens long SQL statements for the terminal
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
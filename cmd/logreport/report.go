@@ -0,0 +1,281 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 16:20
+ * @FilePath: log//cmd/logreport/report.go
+ */
+
+package main
+
+import (
+	"github.com/restoflife/log/logfile"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Report aggregates the access log and the SQL log
+type Report struct {
+	// From and To are the times of the first and the last entry
+	From, To time.Time
+	// Requests is the number of access log entries
+	Requests int
+	// Routes are the requests per method and path, busiest first
+	Routes []*Route
+	// Statuses are the requests per status code, lowest code first
+	Statuses []StatusCount
+	// Classes are the requests per status class such as 2xx
+	Classes []StatusCount
+	// Statements is the number of SQL entries
+	Statements int
+	// Slowest are the slowest SQL statements, slowest first
+	Slowest []Statement
+	// Frequent are the SQL fingerprints, most frequent first
+	Frequent []*Fingerprint
+}
+
+// Route is the latency summary of a method and path
+type Route struct {
+	Method, Path       string
+	Count              int
+	P50, P90, P99, Max time.Duration
+	// Errors are the responses with a 5xx status
+	Errors    int
+	latencies []time.Duration
+}
+
+// StatusCount is the number of responses with a status code or class
+type StatusCount struct {
+	Status string
+	Count  int
+}
+
+// Statement is a single SQL statement
+type Statement struct {
+	Time        time.Time
+	Source      string
+	SQL         string
+	Fingerprint string
+	Latency     time.Duration
+	Error       string
+}
+
+// Fingerprint groups the statements with the same normalised SQL
+type Fingerprint struct {
+	SQL        string
+	Count      int
+	Errors     int
+	Total, Max time.Duration
+}
+
+// Avg returns the mean latency of the statements
+func (f *Fingerprint) Avg() time.Duration {
+	if f.Count == 0 {
+		return 0
+	}
+	return f.Total / time.Duration(f.Count)
+}
+
+// builder collects the entries of a report
+type builder struct {
+	report       Report
+	routes       map[string]*Route
+	statuses     map[int]int
+	fingerprints map[string]*Fingerprint
+	top          int
+}
+
+// newBuilder Create a builder keeping top statements and fingerprints
+func newBuilder(top int) *builder {
+	return &builder{
+		routes:       make(map[string]*Route),
+		statuses:     make(map[int]int),
+		fingerprints: make(map[string]*Fingerprint),
+		top:          top,
+	}
+}
+
+// add adds an entry to the report, entries which are neither requests nor SQL are ignored
+func (b *builder) add(e logfile.Entry) {
+	switch strings.ToUpper(strings.Trim(e.Prefix, "[]")) {
+	case "GIN":
+		if !b.addRequest(e) {
+			return
+		}
+	case "SQL", "GORM", "XORM":
+		b.addStatement(e)
+	default:
+		return
+	}
+	if b.report.From.IsZero() || e.Time.Before(b.report.From) {
+		b.report.From = e.Time
+	}
+	if e.Time.After(b.report.To) {
+		b.report.To = e.Time
+	}
+}
+
+// addRequest adds an access log entry, [GIN] entries without a status code
+// such as gin errors are not requests and false is returned
+func (b *builder) addRequest(e logfile.Entry) bool {
+	code, err := strconv.Atoi(logfile.ValueString(e.Fields["Code"]))
+	if err != nil || code <= 0 {
+		return false
+	}
+	method := logfile.ValueString(e.Fields["Method"])
+	path := logfile.ValueString(e.Fields["Path"])
+	// Group the requests by path, not by query string
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	key := method + " " + path
+	r, ok := b.routes[key]
+	if !ok {
+		r = &Route{Method: method, Path: path}
+		b.routes[key] = r
+	}
+	r.Count++
+	if d, ok := logfile.Duration(e.Fields["Latency"]); ok {
+		r.latencies = append(r.latencies, d)
+	}
+	if code >= 500 {
+		r.Errors++
+	}
+	b.statuses[code]++
+	b.report.Requests++
+	return true
+}
+
+// addStatement adds a SQL entry
+func (b *builder) addStatement(e logfile.Entry) {
+	sql := logfile.ValueString(e.Fields["SQL"])
+	if sql == "" {
+		return
+	}
+	latency, _ := logfile.Duration(e.Fields["Latency"])
+	s := Statement{
+		Time:        e.Time,
+		Source:      e.Prefix,
+		SQL:         sql,
+		Fingerprint: fingerprint(sql),
+		Latency:     latency,
+		Error:       logfile.ValueString(e.Fields["error"]),
+	}
+	f, ok := b.fingerprints[s.Fingerprint]
+	if !ok {
+		f = &Fingerprint{SQL: s.Fingerprint}
+		b.fingerprints[s.Fingerprint] = f
+	}
+	f.Count++
+	f.Total += latency
+	if latency > f.Max {
+		f.Max = latency
+	}
+	if s.Error != "" {
+		f.Errors++
+	}
+	b.report.Statements++
+	// Keep only the slowest statements
+	b.report.Slowest = append(b.report.Slowest, s)
+	if len(b.report.Slowest) > 4*b.top {
+		b.trimSlowest()
+	}
+}
+
+// trimSlowest keeps the top slowest statements, slowest first
+func (b *builder) trimSlowest() {
+	sort.SliceStable(b.report.Slowest, func(i, j int) bool {
+		return b.report.Slowest[i].Latency > b.report.Slowest[j].Latency
+	})
+	if len(b.report.Slowest) > b.top {
+		b.report.Slowest = b.report.Slowest[:b.top]
+	}
+}
+
+// build returns the aggregated report
+func (b *builder) build() Report {
+	b.trimSlowest()
+	r := b.report
+	for _, route := range b.routes {
+		sort.Slice(route.latencies, func(i, j int) bool {
+			return route.latencies[i] < route.latencies[j]
+		})
+		route.P50 = percentile(route.latencies, 50)
+		route.P90 = percentile(route.latencies, 90)
+		route.P99 = percentile(route.latencies, 99)
+		if n := len(route.latencies); n > 0 {
+			route.Max = route.latencies[n-1]
+		}
+		r.Routes = append(r.Routes, route)
+	}
+	sort.Slice(r.Routes, func(i, j int) bool {
+		if r.Routes[i].Count != r.Routes[j].Count {
+			return r.Routes[i].Count > r.Routes[j].Count
+		}
+		return r.Routes[i].Method+r.Routes[i].Path < r.Routes[j].Method+r.Routes[j].Path
+	})
+
+	codes := make([]int, 0, len(b.statuses))
+	for code := range b.statuses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	classes := make(map[string]int)
+	for _, code := range codes {
+		r.Statuses = append(r.Statuses, StatusCount{Status: strconv.Itoa(code), Count: b.statuses[code]})
+		classes[strconv.Itoa(code/100)+"xx"] += b.statuses[code]
+	}
+	for _, class := range []string{"1xx", "2xx", "3xx", "4xx", "5xx"} {
+		if n := classes[class]; n > 0 {
+			r.Classes = append(r.Classes, StatusCount{Status: class, Count: n})
+		}
+	}
+
+	for _, f := range b.fingerprints {
+		r.Frequent = append(r.Frequent, f)
+	}
+	sort.Slice(r.Frequent, func(i, j int) bool {
+		if r.Frequent[i].Count != r.Frequent[j].Count {
+			return r.Frequent[i].Count > r.Frequent[j].Count
+		}
+		return r.Frequent[i].Total > r.Frequent[j].Total
+	})
+	if len(r.Frequent) > b.top {
+		r.Frequent = r.Frequent[:b.top]
+	}
+	return r
+}
+
+// percentile returns the nearest-rank percentile of sorted durations
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+var (
+	// sqlStrings matches quoted literals
+	sqlStrings = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.)*"`)
+	// sqlNumbers matches numeric literals which are not part of an identifier
+	sqlNumbers = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	// sqlLists matches lists of placeholders such as IN (?, ?, ?)
+	sqlLists = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)+\s*\)`)
+	// sqlSpaces matches runs of white space
+	sqlSpaces = regexp.MustCompile(`\s+`)
+)
+
+// fingerprint normalises a SQL statement by replacing its literals with placeholders
+func fingerprint(sql string) string {
+	s := sqlStrings.ReplaceAllString(sql, "?")
+	s = sqlNumbers.ReplaceAllString(s, "?")
+	s = sqlLists.ReplaceAllString(s, "(?+)")
+	s = sqlSpaces.ReplaceAllString(strings.TrimSpace(s), " ")
+	return s
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"github.com/restoflife/log/logfile"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFingerprint(t *testing.T) {
+	cases := map[string]string{
+		"SELECT * FROM `user` WHERE id = 12 AND name = 'o''neil'": "SELECT * FROM `user` WHERE id = ? AND name = ?",
+		"SELECT * FROM t2 WHERE id IN (1, 2,3)":                   "SELECT * FROM t2 WHERE id IN (?+)",
+		"UPDATE t SET  a = 1.5\n WHERE b = \"x\"":                 "UPDATE t SET a = ? WHERE b = ?",
+	}
+	for sql, want := range cases {
+		if got := fingerprint(sql); got != want {
+			t.Errorf("fingerprint(%q) = %q, want %q", sql, got, want)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	lines := []string{
+		`2024-01-02T10:00:00+08:00	INFO	[GIN]	{"Path": "/a?x=1", "Code": 200, "Method": "GET", "Latency": "10ms"}`,
+		`2024-01-02T10:00:01+08:00	INFO	[GIN]	{"Path": "/a", "Code": 200, "Method": "GET", "Latency": "30ms"}`,
+		`2024-01-02T10:00:02+08:00	ERROR	[GIN]	{"Path": "/a", "Code": 502, "Method": "GET", "Latency": "1s"}`,
+		`2024-01-02T10:00:02+08:00	ERROR	[GIN]	{"Error": "bind failed"}`,
+		`2024-01-02T10:00:03+08:00	INFO	[SQL]	{"SQL": "SELECT 1 FROM t WHERE id = 1", "Latency": "5ms"}`,
+		`2024-01-02T10:00:04+08:00	WARN	[GORM]	{"SQL": "SELECT 1 FROM t WHERE id = 2", "Rows": 1, "Latency": "300ms"}`,
+		`2024-01-02T10:00:05+08:00	INFO	started`,
+	}
+	b := newBuilder(10)
+	r := logfile.NewReader(strings.NewReader(strings.Join(lines, "\n")))
+	for {
+		e, err := r.Next()
+		if err != nil {
+			break
+		}
+		b.add(e)
+	}
+	report := b.build()
+	if report.Requests != 3 || report.Statements != 2 || len(report.Routes) != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	route := report.Routes[0]
+	if route.Path != "/a" || route.Errors != 1 || route.P50 != 30*time.Millisecond || route.P99 != time.Second {
+		t.Errorf("unexpected route %+v", route)
+	}
+	if len(report.Frequent) != 1 || report.Frequent[0].Count != 2 || report.Frequent[0].Max != 300*time.Millisecond {
+		t.Errorf("unexpected fingerprints %+v", report.Frequent)
+	}
+	if report.Slowest[0].Source != "[GORM]" {
+		t.Errorf("unexpected slowest %+v", report.Slowest)
+	}
+
+	var text, html bytes.Buffer
+	if err := writeText(&text, report, 10); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Statuses) != 2 || len(report.Classes) != 2 {
+		t.Errorf("unexpected statuses %+v %+v", report.Statuses, report.Classes)
+	}
+	if !strings.Contains(text.String(), "CLASS") || !strings.Contains(text.String(), "5xx") || !strings.Contains(text.String(), "SELECT ? FROM t WHERE id = ?") {
+		t.Errorf("unexpected text report\n%s", text.String())
+	}
+	if err := writeHTML(&html, report, 10); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html.String(), "<td>/a</td>") {
+		t.Errorf("unexpected html report\n%s", html.String())
+	}
+}
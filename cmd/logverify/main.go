@@ -0,0 +1,110 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 19:40
+ * @FilePath: log//cmd/logverify/main.go
+ */
+
+// Command logverify checks the hash chain of the audit log files written by
+// github.com/restoflife/log with Config.Chain set, and reports the entries that
+// were deleted, reordered or edited. It exits with status 1 when a problem is
+// found. A chain starting after the first entry is normal once the retention
+// removed old files, -complete reports it as a problem.
+//
+//	logverify -key "$AUDIT_KEY" -backups audit.log
+//	logverify -key "$AUDIT_KEY" -keys keys.txt -backups audit.log
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/restoflife/log/internal/cli"
+	"github.com/restoflife/log/logfile"
+	"os"
+)
+
+func main() {
+	var (
+		key, keyring string
+		backups      bool
+		complete     bool
+	)
+	flag.StringVar(&key, "key", "", "base64 or hex encoded HMAC key of the chain, empty for SHA-256")
+	flag.StringVar(&keyring, "keys", "", "keyring file used to decrypt encrypted files, one \"id key\" per line")
+	flag.BoolVar(&backups, "backups", false, "also verify the rotated backups of each file, oldest first")
+	flag.BoolVar(&complete, "complete", false, "report a chain which does not start at the first entry, i.e. missing old files")
+	flag.Usage = func() {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "usage: logverify [flags] file...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var (
+		macKey []byte
+		keys   logfile.Keyring
+		err    error
+	)
+	if key != "" {
+		if macKey, err = logfile.ParseMACKey(key); err != nil {
+			cli.Fatal(err)
+		}
+	}
+	if keyring != "" {
+		if keys, err = logfile.ReadKeyring(keyring); err != nil {
+			cli.Fatal(err)
+		}
+	}
+
+	failed := false
+	for _, name := range flag.Args() {
+		files := []string{name}
+		if backups {
+			if files, err = logfile.WithBackups(name); err != nil {
+				cli.Fatal(err)
+			}
+		}
+		// Each file given is a chain of its own, its backups continue it
+		v := logfile.NewVerifier(macKey)
+		for _, fn := range files {
+			if err := verify(v, fn, keys); err != nil {
+				cli.Fatal(err)
+			}
+		}
+		for _, p := range v.Problems {
+			fmt.Println(p)
+		}
+		if v.Start > 0 {
+			if complete {
+				fmt.Printf("%s: chain starts after %d entries, older files are missing\n", name, v.Start)
+				failed = true
+				continue
+			}
+			fmt.Printf("%s: chain starts after %d entries removed by the retention\n", name, v.Start)
+		}
+		if len(v.Problems) > 0 {
+			failed = true
+			continue
+		}
+		fmt.Printf("%s: %d entries in %d files verified\n", name, v.Entries, len(files))
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// verify checks the next file of the chain
+func verify(v *logfile.Verifier, name string, keys logfile.Keyring) error {
+	f, err := logfile.OpenWithKeys(name, keys)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err = v.Verify(name, f); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
@@ -16,4 +16,8 @@ const (
 	GORM = "[GORM]"
 	// SQL defines the prefix of the log entry from SQL
 	SQL = "[SQL]"
+	// GIN defines the prefix of the log entry from the gin middleware
+	GIN = "[GIN]"
+	// ELASTIC defines the prefix of the log entry from Elasticsearch
+	ELASTIC = "[ELASTIC]"
 )
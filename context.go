@@ -0,0 +1,60 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 20:30
+ * @FilePath: log//context.go
+ */
+
+package log
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"sync"
+)
+
+// ContextExtractor returns the fields carried by a context, such as a request or trace ID
+type ContextExtractor func(ctx context.Context) []zap.Field
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   []ContextExtractor
+)
+
+// ctxFieldsKey is the context key of the fields added by WithFields
+type ctxFieldsKey struct{}
+
+// WithFields returns a copy of ctx carrying the fields, they are added to the entries logged with the context
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	prev, _ := ctx.Value(ctxFieldsKey{}).([]zap.Field)
+	all := make([]zap.Field, 0, len(prev)+len(fields))
+	return context.WithValue(ctx, ctxFieldsKey{}, append(append(all, prev...), fields...))
+}
+
+// RegisterContextExtractor adds an extractor called by ContextFields
+func RegisterContextExtractor(fn ContextExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	extractors = append(extractors, fn)
+}
+
+// ContextFields returns the fields added to ctx by WithFields and the registered extractors
+func ContextFields(ctx context.Context) []zap.Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(ctxFieldsKey{}).([]zap.Field)
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+	if len(extractors) == 0 {
+		return fields
+	}
+	fields = fields[:len(fields):len(fields)]
+	for _, fn := range extractors {
+		fields = append(fields, fn(ctx)...)
+	}
+	return fields
+}
@@ -43,6 +43,8 @@ func NewElasticLogger(zapLogger *zap.Logger, requestBody, responseBody bool) *El
 
 // LogRoundTrip Function to log roundtrip information for an Elasticsearch request
 func (l *ElasticsearchLog) LogRoundTrip(req *http.Request, res *http.Response, err error, start time.Time, dur time.Duration) error {
+	// Count the call for the request of the context
+	StatsFromContext(req.Context()).AddElastic(dur)
 	// Unescape the query string
 	query, _ := url.QueryUnescape(req.URL.RawQuery)
 	// If there is a query string, add it to the URL
@@ -63,7 +65,7 @@ func (l *ElasticsearchLog) LogRoundTrip(req *http.Request, res *http.Response, e
 	}
 	// If the request method is not a HEAD request, log the request information
 	if req.Method != http.MethodHead {
-		l.Info("[ELASTIC]",
+		l.Info(ELASTIC,
 			zap.String("Method", req.Method),
 			zap.String("Scheme", req.URL.Scheme),
 			zap.String("Host", req.URL.Host),
@@ -0,0 +1,250 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 17:55
+ * @FilePath: log//file.go
+ */
+
+package log
+
+import (
+	"errors"
+	"fmt"
+	"github.com/restoflife/log/logfile"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
+	"math"
+	"os"
+	"sync"
+)
+
+// EncryptionConfig encrypts the log file with AES-GCM, see package logfile for the file format
+type EncryptionConfig struct {
+	// 密钥 ID, 写入每个文件的文件头
+	KeyID string `json:"key_id"`
+	// base64 或 hex 编码的 AES-128/192/256 密钥
+	Key string `json:"key"`
+	// 每次创建新文件时调用, 返回当前的密钥 ID 和密钥, 用于密钥轮换
+	KeyFunc func() (id string, key []byte, err error) `json:"-"`
+	// 按密钥 ID 返回轮换前的密钥, 启用 Chain 时用于续接上一个文件的哈希链
+	Keys func(id string) ([]byte, error) `json:"-"`
+}
+
+// currentKey returns the key to use for a new file
+func (e *EncryptionConfig) currentKey() (string, []byte, error) {
+	if e.KeyFunc != nil {
+		return e.KeyFunc()
+	}
+	if e.Key == "" {
+		return "", nil, errors.New("log: encryption key is not set")
+	}
+	key, err := logfile.ParseKey(e.Key)
+	return e.KeyID, key, err
+}
+
+// keyByID returns the key of a file written before, by the key ID of its header
+func (e *EncryptionConfig) keyByID(id string) ([]byte, error) {
+	if e.Key != "" && id == e.KeyID {
+		return logfile.ParseKey(e.Key)
+	}
+	if e.Keys != nil {
+		return e.Keys(id)
+	}
+	return nil, fmt.Errorf("%w %q, see EncryptionConfig.Keys", logfile.ErrUnknownKey, id)
+}
+
+// ChainConfig chains the entries of the log file with hashes so that deleted,
+// reordered or edited lines can be found, see package logfile for the format
+type ChainConfig struct {
+	// base64 或 hex 编码的 HMAC 密钥, 为空时使用 SHA-256, 只能发现无意的修改
+	Key string `json:"key"`
+}
+
+// newFileWriter Create the writer of the log file of the configuration
+func (l *Config) newFileWriter() (io.Writer, error) {
+	lj := &lumberjack.Logger{
+		Filename:   l.Filename,
+		MaxSize:    l.MaxSize,
+		MaxBackups: l.MaxBackups,
+		MaxAge:     l.MaxAge,
+		LocalTime:  true,
+	}
+	if l.Encryption == nil && l.Chain == nil {
+		return lj, nil
+	}
+	// A static key is checked now, KeyFunc is called for each new file
+	if l.Encryption != nil && l.Encryption.KeyFunc == nil {
+		if _, _, err := l.Encryption.currentKey(); err != nil {
+			return nil, err
+		}
+	}
+	maxSize := l.MaxSize
+	if maxSize <= 0 {
+		maxSize = 100
+	}
+	// The file is rotated by rotatingFile so that every file starts with its header
+	lj.MaxSize = math.MaxInt32
+	f := &rotatingFile{lj: lj, max: int64(maxSize) * 1024 * 1024}
+
+	var (
+		sealer *logfile.Sealer
+		keyID  string
+		key    []byte
+		chain  *logfile.Chain
+	)
+	if l.Chain != nil {
+		var macKey []byte
+		if l.Chain.Key != "" {
+			var err error
+			if macKey, err = logfile.ParseMACKey(l.Chain.Key); err != nil {
+				return nil, err
+			}
+		}
+		chain = logfile.NewChain(macKey)
+		if err := l.resumeChain(chain); err != nil {
+			return nil, err
+		}
+	}
+	f.header = func() ([]byte, error) {
+		var head []byte
+		if l.Encryption != nil {
+			var err error
+			if keyID, key, err = l.Encryption.currentKey(); err != nil {
+				return nil, err
+			}
+			if sealer, err = logfile.NewSealer(key); err != nil {
+				return nil, err
+			}
+			head = logfile.Header(keyID)
+		}
+		if chain != nil {
+			anchor := chain.Anchor()
+			if sealer != nil {
+				rec, err := sealer.Seal(anchor)
+				if err != nil {
+					return nil, err
+				}
+				anchor = rec
+			}
+			head = append(head, anchor...)
+		}
+		return head, nil
+	}
+	f.seal = func(p []byte) ([]byte, error) {
+		if chain != nil {
+			p = chain.Append(p)
+		}
+		if sealer != nil {
+			return sealer.Seal(p)
+		}
+		return p, nil
+	}
+	return f, nil
+}
+
+// resumeChain continues the chain of the file left by the previous process,
+// the file is read with the key of the key ID of its header
+func (l *Config) resumeChain(chain *logfile.Chain) error {
+	if info, err := os.Stat(l.Filename); err != nil || info.Size() == 0 {
+		return nil
+	}
+	var keys logfile.Keyring
+	if l.Encryption != nil {
+		id, err := logfile.FileKeyID(l.Filename)
+		if err != nil {
+			return fmt.Errorf("log: resume the chain of %s: %w", l.Filename, err)
+		}
+		if id != "" {
+			key, err := l.Encryption.keyByID(id)
+			if err != nil {
+				return fmt.Errorf("log: resume the chain of %s: %w", l.Filename, err)
+			}
+			keys = logfile.Keyring{id: key}
+		}
+	}
+	r, err := logfile.OpenWithKeys(l.Filename, keys)
+	if err != nil {
+		return fmt.Errorf("log: resume the chain of %s: %w", l.Filename, err)
+	}
+	defer r.Close()
+	// A record torn by a crash ends the file, the chain goes on from the last whole entry
+	if err = chain.Resume(r); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("log: resume the chain of %s: %w", l.Filename, err)
+	}
+	return nil
+}
+
+// rotatingFile writes a header at the start of every lumberjack file and seals every write
+type rotatingFile struct {
+	mu sync.Mutex
+	lj *lumberjack.Logger
+	// max is the size in bytes a file is rotated at
+	max int64
+	// size is the size of the current file
+	size int64
+	// headerSize is the size of the header of the current file
+	headerSize int64
+	opened     bool
+	// torn is set when a write failed part way, the rest of the file could not be read
+	torn bool
+	// header is called for each new file and returns its header
+	header func() ([]byte, error)
+	// seal transforms each write into the record written to the file
+	seal func(p []byte) ([]byte, error)
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.opened {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	// Sealing is not repeatable since the chain moves on, so the size of p decides
+	if f.torn || f.size+int64(len(p)) > f.max && f.size > f.headerSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	rec, err := f.seal(p)
+	if err != nil {
+		return 0, err
+	}
+	n, err := f.lj.Write(rec)
+	f.size += int64(n)
+	if err != nil {
+		f.torn = n > 0
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// open starts a new file, an existing file is rotated since its header may belong to another key
+// and its chain has to be anchored
+func (f *rotatingFile) open() error {
+	if info, err := os.Stat(f.lj.Filename); err == nil && info.Size() > 0 {
+		return f.rotate()
+	}
+	return f.start()
+}
+
+// rotate moves the current file to a backup and starts a new one
+func (f *rotatingFile) rotate() error {
+	if err := f.lj.Rotate(); err != nil {
+		return err
+	}
+	return f.start()
+}
+
+// start writes the header of a new file
+func (f *rotatingFile) start() error {
+	head, err := f.header()
+	if err != nil {
+		return err
+	}
+	n, err := f.lj.Write(head)
+	f.size, f.headerSize = int64(n), int64(n)
+	f.opened, f.torn = err == nil, false
+	return err
+}
@@ -0,0 +1,241 @@
+package log
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/restoflife/log/logfile"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncryptedFile(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	name := filepath.Join(t.TempDir(), "secret.log")
+	c := &Config{
+		Level:    "info",
+		Filename: name,
+		Console:  "fatal",
+		Encryption: &EncryptionConfig{
+			KeyID: "k1",
+			Key:   base64.StdEncoding.EncodeToString(key),
+		},
+	}
+	lg := c.NewLogger()
+	lg.Info(GIN, zap.String("Path", "/secret"), zap.Int("Code", 200))
+	lg.Info("second")
+
+	raw, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !logfile.IsEncrypted(raw) || strings.Contains(string(raw), "/secret") {
+		t.Fatalf("file is not encrypted: %q", raw)
+	}
+	if _, err = logfile.Open(name); !errors.Is(err, logfile.ErrEncrypted) {
+		t.Fatalf("expected ErrEncrypted, got %v", err)
+	}
+	msgs := readMessages(t, name, logfile.Keyring{"k1": key})
+	if len(msgs) != 2 || msgs[0] != GIN || msgs[1] != "second" {
+		t.Fatalf("unexpected entries %v", msgs)
+	}
+}
+
+func TestEncryptedFileRotation(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "secret.log")
+	keys := make(logfile.Keyring)
+	n := 0
+	c := &Config{
+		Filename:   name,
+		MaxBackups: 10,
+		Encryption: &EncryptionConfig{
+			// A new key for every file
+			KeyFunc: func() (string, []byte, error) {
+				n++
+				id := fmt.Sprintf("k%d", n)
+				keys[id] = []byte(fmt.Sprintf("%016d", n))
+				return id, keys[id], nil
+			},
+		},
+	}
+	f := rotatingWriter(t, c)
+	f.max = 512
+	for i := 0; i < 20; i++ {
+		// lumberjack names the backups after the time in milliseconds
+		time.Sleep(2 * time.Millisecond)
+		if _, err := fmt.Fprintf(f, "2024-01-02T10:00:00Z\tINFO\tline %02d %s\n", i, strings.Repeat("x", 40)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A restart starts a new file as well
+	time.Sleep(2 * time.Millisecond)
+	f = rotatingWriter(t, &Config{Filename: name, MaxBackups: 10, Encryption: c.Encryption})
+	if _, err := fmt.Fprintf(f, "2024-01-02T10:00:00Z\tINFO\tline %02d\n", 20); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := logfile.WithBackups(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) < 3 || n != len(names) {
+		t.Fatalf("%d files for %d keys", len(names), n)
+	}
+	var msgs []string
+	for _, fn := range names {
+		msgs = append(msgs, readMessages(t, fn, keys)...)
+	}
+	if len(msgs) != 21 || msgs[0] != "line 00 "+strings.Repeat("x", 40) || msgs[20] != "line 20" {
+		t.Fatalf("unexpected entries %v", msgs)
+	}
+}
+
+// rotatingWriter Create the writer of the file of the Config
+func rotatingWriter(t *testing.T, c *Config) *rotatingFile {
+	t.Helper()
+	w, err := c.newFileWriter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w.(*rotatingFile)
+}
+
+// readMessages decrypts the file and returns the messages of its entries
+func readMessages(t *testing.T, name string, keys logfile.Keyring) []string {
+	t.Helper()
+	f, err := logfile.OpenWithKeys(name, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var msgs []string
+	r := logfile.NewReader(f)
+	for {
+		e, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return msgs
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgs = append(msgs, e.Message)
+	}
+}
+
+func TestChainedFile(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	name := filepath.Join(t.TempDir(), "audit.log")
+	c := &Config{
+		Filename:   name,
+		MaxBackups: 10,
+		Chain:      &ChainConfig{Key: "c2VjcmV0"},
+		Encryption: &EncryptionConfig{KeyID: "k1", Key: base64.StdEncoding.EncodeToString(key)},
+	}
+	f := rotatingWriter(t, c)
+	f.max = 512
+	for i := 0; i < 10; i++ {
+		time.Sleep(2 * time.Millisecond)
+		if _, err := fmt.Fprintf(f, "2024-01-02T10:00:00Z\tINFO\tline %02d %s\n", i, strings.Repeat("x", 40)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A restart continues the chain of the previous file
+	time.Sleep(2 * time.Millisecond)
+	f = rotatingWriter(t, &Config{Filename: name, MaxBackups: 10, Chain: c.Chain, Encryption: c.Encryption})
+	if _, err := fmt.Fprintf(f, "2024-01-02T10:00:00Z\tINFO\tline %02d\n", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := logfile.WithBackups(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := logfile.Keyring{"k1": key}
+	v := logfile.NewVerifier([]byte("secret"))
+	var msgs []string
+	for _, fn := range names {
+		r, err := logfile.OpenWithKeys(fn, keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = v.Verify(fn, r)
+		_ = r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgs = append(msgs, readMessages(t, fn, keys)...)
+	}
+	if len(names) < 3 || v.Entries != 11 || len(v.Problems) != 0 {
+		t.Fatalf("%d files, %d entries, problems %v", len(names), v.Entries, v.Problems)
+	}
+	if len(msgs) != 11 || msgs[10] != "line 10" {
+		t.Fatalf("unexpected entries %v", msgs)
+	}
+}
+
+func TestChainedFileKeys(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "audit.log")
+	keys := make(logfile.Keyring)
+	n := 0
+	enc := &EncryptionConfig{
+		// A new key for every file, the old ones are looked up by ID
+		KeyFunc: func() (string, []byte, error) {
+			n++
+			id := fmt.Sprintf("k%d", n)
+			keys[id] = []byte(fmt.Sprintf("%016d", n))
+			return id, keys[id], nil
+		},
+		Keys: func(id string) ([]byte, error) {
+			return keys[id], nil
+		},
+	}
+	c := &Config{Filename: name, MaxBackups: 10, Chain: &ChainConfig{}, Encryption: enc}
+	if _, err := fmt.Fprintln(rotatingWriter(t, c), "2024-01-02T10:00:00Z\tINFO\tfirst"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	c = &Config{Filename: name, MaxBackups: 10, Chain: c.Chain, Encryption: enc}
+	if _, err := fmt.Fprintln(rotatingWriter(t, c), "2024-01-02T10:00:00Z\tINFO\tsecond"); err != nil {
+		t.Fatal(err)
+	}
+	// Only one key was used per file
+	if n != 2 {
+		t.Fatalf("%d keys for 2 files", n)
+	}
+	names, err := logfile.WithBackups(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := logfile.NewVerifier(nil)
+	for _, fn := range names {
+		r, err := logfile.OpenWithKeys(fn, keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = v.Verify(fn, r)
+		_ = r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v.Entries != 2 || len(v.Problems) != 0 {
+		t.Fatalf("%d entries, problems %v", v.Entries, v.Problems)
+	}
+
+	// The chain cannot be continued without the key of the last file
+	c = &Config{Filename: name, Chain: c.Chain, Encryption: &EncryptionConfig{KeyID: "other", Key: "000102030405060708090a0b0c0d0e0f"}}
+	if _, err = c.Build(); !errors.Is(err, logfile.ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey, got %v", err)
+	}
+	// An invalid HMAC key fails the logger
+	c = &Config{Filename: filepath.Join(t.TempDir(), "audit.log"), Chain: &ChainConfig{Key: "!"}}
+	if _, err = c.Build(); err == nil {
+		t.Fatal("expected an error for an invalid HMAC key")
+	}
+}
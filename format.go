@@ -0,0 +1,333 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 22:00
+ * @FilePath: log//format.go
+ */
+
+package log
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats of the log files, see Config.Format
+const (
+	// FormatConsole is the tab separated format with a JSON tail, the default
+	FormatConsole = "console"
+	// FormatJSON writes one JSON object per entry
+	FormatJSON = "json"
+	// FormatGCP writes the JSON expected by Google Cloud Logging
+	FormatGCP = "gcp"
+	// FormatECS writes Elastic Common Schema JSON
+	FormatECS = "ecs"
+	// FormatAWS writes flat JSON suited to CloudWatch Logs Insights and metric filters
+	FormatAWS = "aws"
+)
+
+// fieldMapping renames a field of the gin, SQL and Elasticsearch loggers or a static field and converts its value
+type fieldMapping struct {
+	key string
+	// convert converts the value, nil keeps it
+	convert func(key string, f zapcore.Field) zapcore.Field
+}
+
+// fieldSchema is the field names of a format
+type fieldSchema struct {
+	fields map[string]fieldMapping
+	// groups collect the fields whose key starts with "<group>." into one object, used by GCP httpRequest, serviceContext and labels
+	groups []string
+	// static is added to every entry
+	static []zapcore.Field
+}
+
+// durationNanos converts a duration string such as "1.5ms" to nanoseconds
+func durationNanos(key string, f zapcore.Field) zapcore.Field {
+	if d, ok := fieldDuration(f); ok {
+		return zap.Int64(key, d.Nanoseconds())
+	}
+	return renamed(key, f)
+}
+
+// durationMillis converts a duration string to milliseconds
+func durationMillis(key string, f zapcore.Field) zapcore.Field {
+	if d, ok := fieldDuration(f); ok {
+		return zap.Float64(key, float64(d)/float64(time.Millisecond))
+	}
+	return renamed(key, f)
+}
+
+// durationSeconds converts a duration string to the "1.500s" form of the GCP LogEntry
+func durationSeconds(key string, f zapcore.Field) zapcore.Field {
+	if d, ok := fieldDuration(f); ok {
+		return zap.String(key, strconv.FormatFloat(d.Seconds(), 'f', -1, 64)+"s")
+	}
+	return renamed(key, f)
+}
+
+// intString converts an integer to a string, used by the GCP labels which only hold strings
+func intString(key string, f zapcore.Field) zapcore.Field {
+	if f.Type == zapcore.Int64Type {
+		return zap.String(key, strconv.FormatInt(f.Integer, 10))
+	}
+	return renamed(key, f)
+}
+
+// errorMessage keeps the message of an error field
+func errorMessage(key string, f zapcore.Field) zapcore.Field {
+	if err, ok := f.Interface.(error); ok && f.Type == zapcore.ErrorType {
+		return zap.String(key, err.Error())
+	}
+	return renamed(key, f)
+}
+
+// fieldDuration returns the duration held by a field
+func fieldDuration(f zapcore.Field) (time.Duration, bool) {
+	switch f.Type {
+	case zapcore.DurationType:
+		return time.Duration(f.Integer), true
+	case zapcore.StringType:
+		d, err := time.ParseDuration(f.String)
+		return d, err == nil
+	}
+	return 0, false
+}
+
+// renamed returns the field with another key
+func renamed(key string, f zapcore.Field) zapcore.Field {
+	f.Key = key
+	return f
+}
+
+// schemas maps the formats to their field names, the keys are those written by gin.go, g_logger.go, x_logger.go,
+// elastic.go and the static fields of static.go
+var schemas = map[string]*fieldSchema{
+	FormatGCP: {
+		groups: []string{"httpRequest", "serviceContext", "logging.googleapis.com/labels"},
+		fields: map[string]fieldMapping{
+			"Path":       {key: "httpRequest.requestUrl"},
+			"Code":       {key: "httpRequest.status"},
+			"Method":     {key: "httpRequest.requestMethod"},
+			"User-Agent": {key: "httpRequest.userAgent"},
+			"ClientIP":   {key: "httpRequest.remoteIp"},
+			"Latency":    {key: "httpRequest.latency", convert: durationSeconds},
+			"span_id":    {key: "logging.googleapis.com/spanId"},
+			"Stack":      {key: "stack_trace"},
+			"service":    {key: "serviceContext.service"},
+			"version":    {key: "serviceContext.version"},
+			"env":        {key: "logging.googleapis.com/labels.env"},
+			"hostname":   {key: "logging.googleapis.com/labels.hostname"},
+			"pid":        {key: "logging.googleapis.com/labels.pid", convert: intString},
+		},
+	},
+	FormatECS: {
+		fields: map[string]fieldMapping{
+			"Path":       {key: "url.original"},
+			"Code":       {key: "http.response.status_code"},
+			"Method":     {key: "http.request.method"},
+			"User-Agent": {key: "user_agent.original"},
+			"ClientIP":   {key: "client.ip"},
+			"Latency":    {key: "event.duration", convert: durationNanos},
+			"Time":       {key: "event.duration", convert: durationNanos},
+			"Host":       {key: "url.domain"},
+			"Scheme":     {key: "url.scheme"},
+			"SQL":        {key: "db.statement"},
+			"Rows":       {key: "db.rows"},
+			"error":      {key: "error.message", convert: errorMessage},
+			"Error":      {key: "error.message"},
+			"Stack":      {key: "error.stack_trace"},
+			"trace_id":   {key: "trace.id"},
+			"span_id":    {key: "span.id"},
+			"request_id": {key: "http.request.id"},
+			"service":    {key: "service.name"},
+			"env":        {key: "service.environment"},
+			"version":    {key: "service.version"},
+			"hostname":   {key: "host.hostname"},
+			"pid":        {key: "process.pid"},
+		},
+		static: []zapcore.Field{zap.String("ecs.version", "8.11.0")},
+	},
+	FormatAWS: {
+		fields: map[string]fieldMapping{
+			"Path":       {key: "path"},
+			"Code":       {key: "status"},
+			"Method":     {key: "method"},
+			"User-Agent": {key: "user_agent"},
+			"ClientIP":   {key: "client_ip"},
+			"Latency":    {key: "latency_ms", convert: durationMillis},
+			"Time":       {key: "latency_ms", convert: durationMillis},
+			"Host":       {key: "host"},
+			"Scheme":     {key: "scheme"},
+			"SQL":        {key: "sql"},
+			"Rows":       {key: "rows"},
+			"Error":      {key: "error"},
+			"Stack":      {key: "stack"},
+			"env":        {key: "environment"},
+			"version":    {key: "service_version"},
+		},
+	},
+}
+
+// newFileEncoder Create the encoder of the log files for the format
+func (l *Config) newFileEncoder() zapcore.Encoder {
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.EncodeTime = timeEncoder
+	cfg.EncodeLevel = zapcore.CapitalLevelEncoder
+	cfg.EncodeDuration = zapcore.SecondsDurationEncoder
+	cfg.EncodeCaller = zapcore.ShortCallerEncoder
+
+	switch strings.ToLower(l.Format) {
+	case FormatJSON:
+	case FormatGCP:
+		cfg.TimeKey, cfg.LevelKey, cfg.MessageKey = "time", "severity", "message"
+		cfg.StacktraceKey = "stack_trace"
+		cfg.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+		cfg.EncodeLevel = gcpLevelEncoder
+		cfg.EncodeDuration = zapcore.StringDurationEncoder
+	case FormatECS:
+		cfg.TimeKey, cfg.LevelKey, cfg.MessageKey = "@timestamp", "log.level", "message"
+		cfg.NameKey, cfg.CallerKey, cfg.StacktraceKey = "log.logger", "log.origin.file.name", "error.stack_trace"
+		cfg.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+		cfg.EncodeLevel = zapcore.LowercaseLevelEncoder
+		cfg.EncodeDuration = zapcore.NanosDurationEncoder
+	case FormatAWS:
+		cfg.TimeKey, cfg.MessageKey = "timestamp", "message"
+		cfg.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+		cfg.EncodeDuration = zapcore.MillisDurationEncoder
+	default:
+		return zapcore.NewConsoleEncoder(cfg)
+	}
+	return zapcore.NewJSONEncoder(cfg)
+}
+
+// gcpLevelEncoder encodes the level as a LogSeverity of Google Cloud Logging
+func gcpLevelEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	switch l {
+	case zapcore.DebugLevel:
+		enc.AppendString("DEBUG")
+	case zapcore.InfoLevel:
+		enc.AppendString("INFO")
+	case zapcore.WarnLevel:
+		enc.AppendString("WARNING")
+	case zapcore.ErrorLevel:
+		enc.AppendString("ERROR")
+	case zapcore.DPanicLevel, zapcore.PanicLevel:
+		enc.AppendString("CRITICAL")
+	case zapcore.FatalLevel:
+		enc.AppendString("ALERT")
+	default:
+		enc.AppendString("DEFAULT")
+	}
+}
+
+// newFileCore Create the core of a log file, the fields are renamed for the format
+func (l *Config) newFileCore(enc zapcore.Encoder, ws zapcore.WriteSyncer, enab zapcore.LevelEnabler) zapcore.Core {
+	core := zapcore.NewCore(enc, ws, enab)
+	s, ok := schemas[strings.ToLower(l.Format)]
+	if !ok {
+		return core
+	}
+	if s == schemas[FormatGCP] && l.GCPProject != "" {
+		s = s.withGCPTrace(l.GCPProject)
+	}
+	if len(s.static) > 0 {
+		core = core.With(s.static)
+	}
+	return &schemaCore{Core: core, schema: s}
+}
+
+// schemaCore renames the fields to the names of a format
+type schemaCore struct {
+	zapcore.Core
+	schema *fieldSchema
+}
+
+func (c *schemaCore) With(fields []zapcore.Field) zapcore.Core {
+	return &schemaCore{Core: c.Core.With(c.schema.rename(fields)), schema: c.schema}
+}
+
+func (c *schemaCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *schemaCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, c.schema.rename(fields))
+}
+
+// withGCPTrace returns a copy of the schema writing the trace IDs as the trace
+// resource names Cloud Logging links to Cloud Trace
+func (s *fieldSchema) withGCPTrace(project string) *fieldSchema {
+	clone := *s
+	clone.fields = make(map[string]fieldMapping, len(s.fields)+1)
+	for k, m := range s.fields {
+		clone.fields[k] = m
+	}
+	clone.fields["trace_id"] = fieldMapping{
+		key: "logging.googleapis.com/trace",
+		convert: func(key string, f zapcore.Field) zapcore.Field {
+			if f.Type == zapcore.StringType {
+				return zap.String(key, "projects/"+project+"/traces/"+f.String)
+			}
+			return renamed(key, f)
+		},
+	}
+	return &clone
+}
+
+// rename returns the fields with the names of the schema
+func (s *fieldSchema) rename(fields []zapcore.Field) []zapcore.Field {
+	var (
+		out    = make([]zapcore.Field, 0, len(fields))
+		groups = make([]groupFields, len(s.groups))
+	)
+	for _, f := range fields {
+		m, ok := s.fields[f.Key]
+		if !ok {
+			out = append(out, f)
+			continue
+		}
+		if m.convert != nil {
+			f = m.convert(m.key, f)
+		} else {
+			f = renamed(m.key, f)
+		}
+		if i := s.group(f.Key); i >= 0 {
+			groups[i] = append(groups[i], renamed(f.Key[len(s.groups[i])+1:], f))
+			continue
+		}
+		out = append(out, f)
+	}
+	for i, group := range groups {
+		if len(group) > 0 {
+			out = append(out, zap.Object(s.groups[i], group))
+		}
+	}
+	return out
+}
+
+// group returns the index of the group of a key, -1 when the key belongs to none
+func (s *fieldSchema) group(key string) int {
+	for i, g := range s.groups {
+		if strings.HasPrefix(key, g+".") {
+			return i
+		}
+	}
+	return -1
+}
+
+// groupFields encodes fields as an object
+type groupFields []zapcore.Field
+
+func (g groupFields) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, f := range g {
+		f.AddTo(enc)
+	}
+	return nil
+}
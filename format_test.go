@@ -0,0 +1,106 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormats(t *testing.T) {
+	host, _ := os.Hostname()
+	pid := os.Getpid()
+	cases := []struct {
+		format  string
+		project string
+		want    map[string]interface{}
+	}{
+		{FormatJSON, "", map[string]interface{}{
+			"level": "INFO", "msg": GIN, "Path": "/a?b=1", "Code": 200.0, "Latency": "1.5s", "error": "boom",
+			"service": "api", "env": "prod", "version": "1.2.3", "hostname": host, "pid": float64(pid),
+		}},
+		{FormatGCP, "", map[string]interface{}{
+			"severity": "INFO", "message": GIN, "error": "boom",
+			"httpRequest":                  map[string]interface{}{"requestUrl": "/a?b=1", "status": 200.0, "requestMethod": "GET", "latency": "1.5s"},
+			"trace_id":                     "4bf92f3577b34da6a3ce929d0e0e4736",
+			"logging.googleapis.com/trace": nil,
+			"serviceContext":               map[string]interface{}{"service": "api", "version": "1.2.3"},
+			"logging.googleapis.com/labels": map[string]interface{}{
+				"env": "prod", "hostname": host, "pid": strconv.Itoa(pid),
+			},
+			"service": nil, "env": nil, "version": nil, "hostname": nil, "pid": nil,
+		}},
+		{FormatGCP, "my-project", map[string]interface{}{
+			"logging.googleapis.com/trace": "projects/my-project/traces/4bf92f3577b34da6a3ce929d0e0e4736",
+			"trace_id":                     nil,
+		}},
+		{FormatECS, "", map[string]interface{}{
+			"log.level": "info", "message": GIN, "ecs.version": "8.11.0", "error.message": "boom",
+			"url.original": "/a?b=1", "http.response.status_code": 200.0, "http.request.method": "GET", "event.duration": 1.5e9,
+			"trace.id":     "4bf92f3577b34da6a3ce929d0e0e4736",
+			"service.name": "api", "service.environment": "prod", "service.version": "1.2.3",
+			"host.hostname": host, "process.pid": float64(pid),
+			"service": nil, "env": nil, "version": nil, "hostname": nil, "pid": nil,
+		}},
+		{FormatAWS, "", map[string]interface{}{
+			"level": "INFO", "message": GIN, "error": "boom",
+			"path": "/a?b=1", "status": 200.0, "method": "GET", "latency_ms": 1500.0,
+			"service": "api", "environment": "prod", "service_version": "1.2.3", "hostname": host, "pid": float64(pid),
+		}},
+	}
+	for _, tc := range cases {
+		name := filepath.Join(t.TempDir(), "app.log")
+		c := &Config{
+			Level:      "info",
+			Filename:   name,
+			Console:    "fatal",
+			Format:     tc.format,
+			GCPProject: tc.project,
+			Service:    "api",
+			Env:        "prod",
+			Version:    "1.2.3",
+		}
+		c.NewLogger().With(zap.String("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736")).Info(GIN,
+			zap.String("Path", "/a?b=1"),
+			zap.Int("Code", 200),
+			zap.String("Method", "GET"),
+			zap.String("Latency", (1500*time.Millisecond).String()),
+			zap.Error(errors.New("boom")),
+		)
+		b, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got map[string]interface{}
+		if err = json.Unmarshal(b, &got); err != nil {
+			t.Fatalf("%s: %v: %s", tc.format, err, b)
+		}
+		for k, want := range tc.want {
+			if !reflect.DeepEqual(got[k], want) {
+				t.Errorf("%s: %s = %#v, want %#v", tc.format, k, got[k], want)
+			}
+		}
+		if !strings.HasSuffix(string(b), "}\n") {
+			t.Errorf("%s: not one object per line: %s", tc.format, b)
+		}
+	}
+}
+
+func TestDurationSeconds(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		1500 * time.Millisecond: "1.5s",
+		50 * time.Microsecond:   "0.00005s",
+		time.Nanosecond:         "0.000000001s",
+		2 * time.Minute:         "120s",
+	} {
+		if got := durationSeconds("latency", zap.String("Latency", d.String())); got.String != want {
+			t.Errorf("%s: got %q, want %q", d, got.String, want)
+		}
+	}
+}
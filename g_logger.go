@@ -88,11 +88,13 @@ func (l GormLogger) Error(_ context.Context, str string, args ...interface{}) {
 	l.logger.Error(fmt.Sprintf(str, args...))
 }
 
-func (l GormLogger) Trace(_ context.Context, begin time.Time, fc func() (string, int64), err error) {
+func (l GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+	// Count the statement for the request even when it is not logged
+	StatsFromContext(ctx).AddSQL(elapsed)
 	if l.LogLevel <= 0 {
 		return
 	}
-	elapsed := time.Since(begin)
 	sql, rows := fc()
 	switch {
 	case err != nil && l.LogLevel >= glog.Error && (!l.IgnoreRecordNotFoundError || !errors.Is(err, gorm.ErrRecordNotFound)):
@@ -103,7 +105,7 @@ func (l GormLogger) Trace(_ context.Context, begin time.Time, fc func() (string,
 	// Check if the logger is enabled
 	if check := l.logger.Check(l.logLvl, GORM); check != nil {
 		// Write the SQL statement, execution time, and error to the logger
-		check.Write(zap.String("SQL", sql), zap.Int64("Rows", rows), zap.String("Latency", elapsed.String()), zap.Error(err))
+		check.Write(append([]zap.Field{zap.String("SQL", sql), zap.Int64("Rows", rows), zap.String("Latency", elapsed.String()), zap.Error(err)}, ContextFields(ctx)...)...)
 	}
 
 	//lg.Check(level, GORM).Write(zap.String("SQL", sql), zap.Int64("rows", rows), zap.String("latency", elapsed.String()), zap.Error(err))
@@ -8,24 +8,30 @@
 package log
 
 import (
+	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/mattn/go-isatty"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"io"
+	"math/rand"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"os"
-	"runtime"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 )
 
 var (
-	// 堆栈池
+	// 堆栈池, 堆栈超出时按需扩容
 	stackPool = sync.Pool{
 		// New 方法用于分配新的堆栈内存
 		New: func() interface{} {
-			return make([]byte, 64<<10)
+			return make([]byte, stackBufSize)
 		},
 	}
 	// 日志排除路径
@@ -34,12 +40,140 @@ var (
 
 // ConfigGin defines the config for Logger middleware.
 type ConfigGin struct {
-	// Output is a writer where logs are written.
+	// Output is a writer where logs are written, see AccessLog.
+	// Optional. Default value is gin.DefaultWriter.
 	Output io.Writer
 
 	// SkipPaths is a url path array which logs are not written.
 	// Optional.
 	SkipPaths []string
+
+	// SkipRules are requests which logs are not written, e.g. by path prefix,
+	// route template, status or user agent. WithConfig panics when a PathRegex
+	// of SkipRules or Sampling is invalid.
+	// Optional.
+	SkipRules []RequestMatcher
+
+	// Sampling logs a share of the matching requests, the first matching rule
+	// applies. Requests logged at Warn or above and requests with errors are
+	// always logged, the bodies of a request sampled out are captured only
+	// with BodyCapture.OnlyErrors.
+	// Optional.
+	Sampling []SampleRule
+
+	// SlowThreshold logs the requests taking longer at Warn with the slow and
+	// SlowThreshold fields.
+	// Optional.
+	SlowThreshold time.Duration
+
+	// SlowRoutes are the slow thresholds of routes by template, e.g.
+	// /reports/:id, overriding SlowThreshold.
+	// Optional.
+	SlowRoutes map[string]time.Duration
+
+	// ServerTiming adds a Server-Timing response header with the server time
+	// and the slow threshold when exceeded.
+	// Optional.
+	ServerTiming bool
+
+	// DownstreamStats adds the number and total time of the SQL statements
+	// and Elasticsearch calls of the request as SQLCount, SQLTime, ESCount and
+	// ESTime. The queries must use the request context, e.g.
+	// db.WithContext(c.Request.Context()).
+	// Optional.
+	DownstreamStats bool
+
+	// Headers logs the selected request and response headers, credentials
+	// are masked.
+	// Optional.
+	Headers *HeaderPolicy
+
+	// Formatter returns the fields of the access log entry, see FormatterPreset.
+	// Optional. Default value is the default preset.
+	Formatter LogFormatter
+
+	// Fields keeps only the named fields of the formatter, Errors, the headers and the bodies, e.g.
+	// Path, Code and Latency. The request and trace IDs are always logged.
+	// Optional.
+	Fields []string
+
+	// BodyCapture logs the request and response bodies.
+	// Optional.
+	BodyCapture *BodyCaptureConfig
+
+	// AccessLog writes a line per logged request to Output, e.g.
+	// CombinedLogLine or CommonLogLine for the tools reading the Apache
+	// formats.
+	// Optional.
+	AccessLog func(params FormatterParams) string
+
+	// AccessLogOnly writes the AccessLog line instead of the zap entry.
+	// Optional.
+	AccessLogOnly bool
+
+	// StatusLevels chooses the level of the access log by status, the first
+	// matching rule applies and Info is used otherwise. nil uses
+	// DefaultStatusLevels, an empty slice logs every request at Info.
+	// Optional.
+	StatusLevels []StatusLevel
+}
+
+// StatusLevel logs the responses with a status within [Min, Max] at Level
+type StatusLevel struct {
+	Min, Max int
+	Level    zapcore.Level
+}
+
+// DefaultStatusLevels logs 5xx responses at Error and 4xx responses at Warn
+var DefaultStatusLevels = []StatusLevel{
+	{Min: 500, Max: 599, Level: zapcore.ErrorLevel},
+	{Min: 400, Max: 499, Level: zapcore.WarnLevel},
+}
+
+// statusLevel returns the level of the status
+func statusLevel(rules []StatusLevel, status int) zapcore.Level {
+	for _, r := range rules {
+		if status >= r.Min && status <= r.Max {
+			return r.Level
+		}
+	}
+	return zapcore.InfoLevel
+}
+
+// ginErrors encodes the errors of a gin.Context with their type and meta
+type ginErrors []*gin.Error
+
+func (errs ginErrors) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, e := range errs {
+		e := e
+		_ = enc.AppendObject(zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("Error", e.Error())
+			enc.AddString("Type", ginErrorType(e.Type))
+			if e.Meta != nil {
+				return enc.AddReflected("Meta", e.Meta)
+			}
+			return nil
+		}))
+	}
+	return nil
+}
+
+// ginErrorType returns the name of the type of a gin error
+func ginErrorType(t gin.ErrorType) string {
+	switch {
+	case t == gin.ErrorTypeAny:
+		return "any"
+	case t&gin.ErrorTypeBind != 0:
+		return "bind"
+	case t&gin.ErrorTypeRender != 0:
+		return "render"
+	case t&gin.ErrorTypePublic != 0:
+		return "public"
+	case t&gin.ErrorTypePrivate != 0:
+		return "private"
+	default:
+		return "other"
+	}
 }
 
 // FormatterParams is the structure any formatter will be handed when time to log comes
@@ -64,6 +198,8 @@ type FormatterParams struct {
 	BodySize int
 	// Keys are the keys set on the request's context.
 	Keys map[string]interface{}
+	// TimeStamp shows the time after the server returns a response.
+	TimeStamp time.Time
 }
 
 // GinLogger instances a Logger middleware that will write the logs to gin.DefaultWriter.
@@ -93,6 +229,24 @@ func WithConfig(log *zap.Logger, conf ConfigGin) gin.HandlerFunc {
 	if w, ok := out.(*os.File); !ok || os.Getenv("TERM") == "dumb" || (!isatty.IsTerminal(w.Fd()) && !isatty.IsCygwinTerminal(w.Fd())) {
 		isTerm = false
 	}
+	// Use the default status rules unless set
+	statusLevels := conf.StatusLevels
+	if statusLevels == nil {
+		statusLevels = DefaultStatusLevels
+	}
+	formatter := conf.Formatter
+	if formatter == nil {
+		formatter = defaultFormatter
+	}
+	keep := make(map[string]struct{})
+	for _, name := range conf.Fields {
+		keep[name] = struct{}{}
+	}
+	filter := newAccessFilter(conf)
+	var access *accessWriter
+	if conf.AccessLog != nil {
+		access = &accessWriter{w: out, line: conf.AccessLog}
+	}
 	// Create a map of paths to skip
 	skip := make(map[string]struct{})
 	for _, path := range conf.SkipPaths {
@@ -106,18 +260,44 @@ func WithConfig(log *zap.Logger, conf ConfigGin) gin.HandlerFunc {
 		path := c.Request.URL.Path
 		// Set the raw query to the request URL raw query
 		raw := c.Request.URL.RawQuery
+		_, skipped := skip[path]
+		// The sampling draw is made now so that the requests dropped whatever
+		// their response are known before their bodies are captured
+		roll := rand.Float64()
+		sampling := conf.BodyCapture == nil || !conf.BodyCapture.OnlyErrors
+		// Capture the bodies before the handlers read and write them
+		var bodies *bodyCapture
+		if !skipped && !filter.early(c, roll, sampling) {
+			bodies = conf.BodyCapture.newBodyCapture(c)
+		}
+		var stats *RequestStats
+		if conf.DownstreamStats {
+			var ctx context.Context
+			ctx, stats = ContextWithStats(c.Request.Context())
+			c.Request = c.Request.WithContext(ctx)
+		}
+		threshold := conf.slowThreshold(c)
+		var timing *timingWriter
+		if conf.ServerTiming {
+			timing = newTimingWriter(c, start, threshold)
+		}
 		// Call the next handler
 		c.Next()
+		// Responses without body are written by gin after the middlewares
+		if timing != nil {
+			timing.header()
+		}
 		// If the path is not in the skip map
-		if _, ok := skip[path]; !ok {
+		if !skipped {
 			// Create a FormatterParams struct
 			param := FormatterParams{
 				Request: c.Request,
 				isTerm:  isTerm,
 				Keys:    c.Keys,
 			}
-			// Set the latency to the difference between the current time and the start time
-			param.Latency = time.Since(start)
+			// Set the time stamp and the latency to the difference between the current time and the start time
+			param.TimeStamp = time.Now()
+			param.Latency = param.TimeStamp.Sub(start)
 			// Set the client IP to the request client IP
 			param.ClientIP = c.ClientIP()
 			// Set the method to the request method
@@ -135,51 +315,143 @@ func WithConfig(log *zap.Logger, conf ConfigGin) gin.HandlerFunc {
 			}
 			// Set the path to the parameter
 			param.Path = path
-			// If the error message is empty
-			if len(param.ErrorMessage) == 0 {
-				// Log the request path, status code, method, user agent, latency, and the request
-				log.Info("[GIN]",
-					zap.String("Path", path),
-					zap.Int("Code", param.StatusCode),
-					zap.String("Method", param.Method),
-					zap.String("User-Agent", c.Request.UserAgent()),
-					zap.String("Latency", param.Latency.String()),
-				)
-				// If the error message is not empty
-			} /*else {
-				// Log the error message
-				log.Error("[GIN]",
-					zap.String("Path", c.Request.URL.Path),
-					zap.String("Error", param.ErrorMessage))
-			}*/
+			// Log the fields of the formatter and the errors at the level of the status
+			level := statusLevel(statusLevels, param.StatusCode)
+			slow := threshold > 0 && param.Latency > threshold
+			if slow && level < zapcore.WarnLevel {
+				level = zapcore.WarnLevel
+			}
+			if filter.drop(c, level >= zapcore.WarnLevel || len(c.Errors) > 0, roll) {
+				return
+			}
+			if access != nil {
+				access.write(param)
+				if conf.AccessLogOnly {
+					return
+				}
+			}
+			if ce := log.Check(level, GIN); ce != nil {
+				fields := formatter(param)
+				if len(c.Errors) > 0 {
+					fields = append(fields, zap.Array("Errors", ginErrors(c.Errors)))
+				}
+				if stats != nil {
+					fields = append(fields, stats.Fields()...)
+				}
+				fields = append(fields, conf.Headers.fields(c.Request.Header, c.Writer.Header())...)
+				fields = append(fields, bodies.fields(c)...)
+				if slow {
+					fields = append(fields, zap.Bool("slow", true), zap.String("SlowThreshold", threshold.String()))
+				}
+				ce.Write(append(selectFields(fields, keep), requestFields(c)...)...)
+			}
 		}
 	}
 }
 
+// RecoveryConfig defines the config for Recovery middleware.
+type RecoveryConfig struct {
+	// Headers selects the headers of the request dump and masks the
+	// credentials.
+	// Optional. Default value masks DefaultRedactedHeaders.
+	Headers *HeaderPolicy
+
+	// Handler writes the response after the panic is logged, e.g. an error
+	// page. The request is aborted afterwards. Handler and JSON are not used
+	// when the handler has already written the response.
+	// Optional. Default value aborts with a bare 500, see JSON.
+	Handler func(c *gin.Context, err interface{})
+
+	// JSON responds with {"error": "Internal Server Error", "request_id": ...}
+	// when Handler is not set.
+	// Optional.
+	JSON bool
+
+	// MaxDumpBody is the number of bytes of the request body in the dump,
+	// -1 leaves the body out.
+	// Optional. Default value is 4096.
+	MaxDumpBody int
+
+	// AllGoroutines adds the stacks of all goroutines as Goroutines.
+	// Optional.
+	AllGoroutines bool
+
+	// RepanicAbortHandler panics again with http.ErrAbortHandler without
+	// logging, so that net/http aborts the response as the handler asked.
+	// Optional.
+	RepanicAbortHandler bool
+}
+
 // Recovery This function is used to recover from panic and log the error
 func Recovery(logger *zap.Logger) gin.HandlerFunc {
+	return RecoveryWithConfig(logger, RecoveryConfig{})
+}
+
+// RecoveryWithConfig instance a Recovery middleware with config. Panics
+// caused by a client which went away, a broken pipe or a connection reset,
+// are logged at Warn without stack nor dump since no response can be written.
+func RecoveryWithConfig(logger *zap.Logger, conf RecoveryConfig) gin.HandlerFunc {
+	headers := conf.Headers
+	if headers == nil {
+		headers = defaultHeaderPolicy
+	}
+	maxBody := conf.MaxDumpBody
+	if maxBody == 0 {
+		maxBody = 4096
+	}
 	return func(c *gin.Context) {
-		// Get a stack from the pool
-		stack := stackPool.Get().([]byte)
-		// Defer the put of the stack back into the pool until the function returns
-		defer stackPool.Put(stack[:0])
 		// Defer the execution of the code until the function returns
 		defer func() {
-			// Create a byte array to store the request
-			var rawReq []byte
-			// If the request is not nil, dump it into the byte array
-			if c.Request != nil {
-				rawReq, _ = httputil.DumpRequest(c.Request, true)
+			err := recover()
+			if err == nil {
+				return
+			}
+			if conf.RepanicAbortHandler && err == http.ErrAbortHandler {
+				panic(err)
 			}
-			// If there is a panic, log the error
-			if err := recover(); err != nil {
-				stack = stack[:runtime.Stack(stack, false)]
-				logger.Error("[Recovery]",
+			// The client is gone, there is nobody to respond to
+			if isBrokenPipe(err) {
+				logger.Warn("[Recovery]", append([]zap.Field{
 					zap.String("Path", c.Request.RequestURI),
 					zap.Any("Error", err),
-					zap.ByteString("Request", rawReq),
-					zap.String("Stack", string(stack)),
-				)
+					zap.Bool("BrokenPipe", true),
+				}, requestFields(c)...)...)
+				if e, ok := err.(error); ok {
+					_ = c.Error(e)
+				}
+				c.Abort()
+				return
+			}
+			// The application frames of the panic, the raw stack keeps all of them
+			frames := callerFrames()
+			fields := []zap.Field{
+				zap.String("Path", c.Request.RequestURI),
+				zap.Any("Error", err),
+				zap.ByteString("Request", dumpRequest(c.Request, headers, maxBody)),
+				zap.String("Stack", stackDump(false)),
+				zap.Array("Frames", stackFrames(frames)),
+				zap.String("Fingerprint", StackFingerprint(err, frames)),
+			}
+			if conf.AllGoroutines {
+				fields = append(fields, zap.String("Goroutines", stackDump(true)))
+			}
+			logger.Error("[Recovery]", append(fields, requestFields(c)...)...)
+			// The response has started, only the rest of the handlers can be stopped
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			switch {
+			case conf.Handler != nil:
+				conf.Handler(c, err)
+				c.Abort()
+			case conf.JSON:
+				body := gin.H{"error": http.StatusText(http.StatusInternalServerError)}
+				if id := RequestIDFromGin(c); id != "" {
+					body["request_id"] = id
+				}
+				c.AbortWithStatusJSON(http.StatusInternalServerError, body)
+			default:
 				// Abort the request with an internal server error
 				c.AbortWithStatus(http.StatusInternalServerError)
 			}
@@ -188,3 +460,46 @@ func Recovery(logger *zap.Logger) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// dumpRequest dumps the request with the header policy applied and at most max bytes of its body,
+// the body captured by the access log is used since the handler has usually read the body
+func dumpRequest(r *http.Request, headers *HeaderPolicy, max int) []byte {
+	if r == nil {
+		return nil
+	}
+	req := *r
+	req.Header = headers.sanitize(r.Header)
+	dump, err := httputil.DumpRequest(&req, false)
+	if err != nil || max < 0 || r.Body == nil || r.Body == http.NoBody {
+		return dump
+	}
+	body, truncated := capturedRequestBody(r)
+	if body == nil {
+		body, _ = io.ReadAll(io.LimitReader(r.Body, int64(max)+1))
+	}
+	if len(body) > max {
+		body, truncated = body[:max], true
+	}
+	dump = append(dump, body...)
+	if truncated {
+		dump = append(dump, "...(truncated)"...)
+	}
+	return dump
+}
+
+// isBrokenPipe reports whether the panic is caused by a connection closed by the client
+func isBrokenPipe(err interface{}) bool {
+	e, ok := err.(error)
+	if !ok {
+		return false
+	}
+	if errors.Is(e, syscall.EPIPE) || errors.Is(e, syscall.ECONNRESET) {
+		return true
+	}
+	var ne *net.OpError
+	if errors.As(e, &ne) {
+		msg := strings.ToLower(ne.Error())
+		return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+	}
+	return false
+}
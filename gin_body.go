@@ -0,0 +1,193 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 23:55
+ * @FilePath: log//gin_body.go
+ */
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DefaultBodyContentTypes are the content types captured when BodyCaptureConfig.ContentTypes is empty
+var DefaultBodyContentTypes = []string{"application/json", "application/x-www-form-urlencoded", "application/xml", "text/"}
+
+// BodyCaptureConfig defines which request and response bodies the access log contains
+type BodyCaptureConfig struct {
+	// Request logs the request body as RequestBody
+	Request bool
+	// Response logs the response body as ResponseBody
+	Response bool
+	// MaxSize is the number of bytes logged of each body. Default value is 4096.
+	MaxSize int
+	// ContentTypes are the media types captured, a trailing / matches the
+	// subtypes. Default value is DefaultBodyContentTypes.
+	ContentTypes []string
+	// Paths are the routes captured, as registered such as /users/:id, or the
+	// request path. Optional, all when empty.
+	Paths []string
+	// Methods are the HTTP methods captured. Optional, all when empty.
+	Methods []string
+	// OnlyErrors logs the bodies of the responses with a status of 400 or more only.
+	OnlyErrors bool
+}
+
+// ctxBodyKey is the context key of the bodyCapture, read by the Recovery dump
+type ctxBodyKey struct{}
+
+// bodyCapture is the capture of the bodies of one request
+type bodyCapture struct {
+	conf      *BodyCaptureConfig
+	request   []byte
+	truncated bool
+	writer    *bodyWriter
+}
+
+// newBodyCapture starts capturing the bodies of the request, nil when it is not captured
+func (b *BodyCaptureConfig) newBodyCapture(c *gin.Context) *bodyCapture {
+	if b == nil || (!b.Request && !b.Response) {
+		return nil
+	}
+	if len(b.Methods) > 0 && !containsFold(b.Methods, c.Request.Method) {
+		return nil
+	}
+	if len(b.Paths) > 0 && !contains(b.Paths, c.FullPath()) && !contains(b.Paths, c.Request.URL.Path) {
+		return nil
+	}
+	max := b.maxSize()
+	capture := &bodyCapture{conf: b}
+	if b.Request && c.Request.Body != nil && c.Request.Body != http.NoBody && b.allowed(c.GetHeader("Content-Type")) {
+		// Read one byte more to know whether the body is truncated, the handlers read it all
+		buf, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(max)+1))
+		c.Request.Body = &replayBody{Reader: io.MultiReader(bytes.NewReader(buf), c.Request.Body), Closer: c.Request.Body}
+		if err == nil || len(buf) > 0 {
+			capture.request, capture.truncated = buf, len(buf) > max
+			if capture.truncated {
+				capture.request = capture.request[:max]
+			}
+		}
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), ctxBodyKey{}, capture))
+	}
+	if b.Response {
+		capture.writer = &bodyWriter{ResponseWriter: c.Writer, max: max}
+		c.Writer = capture.writer
+	}
+	return capture
+}
+
+// capturedRequestBody returns the request body captured by the access log, nil when there is none
+func capturedRequestBody(r *http.Request) (body []byte, truncated bool) {
+	if b, _ := r.Context().Value(ctxBodyKey{}).(*bodyCapture); b != nil {
+		return b.request, b.truncated
+	}
+	return nil, false
+}
+
+// fields returns the fields of the captured bodies
+func (b *bodyCapture) fields(c *gin.Context) []zap.Field {
+	if b == nil || (b.conf.OnlyErrors && c.Writer.Status() < 400) {
+		return nil
+	}
+	var fields []zap.Field
+	if b.request != nil {
+		fields = append(fields, zap.ByteString("RequestBody", b.request))
+		if b.truncated {
+			fields = append(fields, zap.Bool("RequestBodyTruncated", true))
+		}
+	}
+	if w := b.writer; w != nil && w.buf.Len() > 0 && b.conf.allowed(w.Header().Get("Content-Type")) {
+		fields = append(fields, zap.ByteString("ResponseBody", w.buf.Bytes()))
+		if w.truncated {
+			fields = append(fields, zap.Bool("ResponseBodyTruncated", true))
+		}
+	}
+	return fields
+}
+
+// maxSize returns the number of bytes logged of each body
+func (b *BodyCaptureConfig) maxSize() int {
+	if b.MaxSize > 0 {
+		return b.MaxSize
+	}
+	return 4096
+}
+
+// allowed reports whether the body of the content type is captured
+func (b *BodyCaptureConfig) allowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	types := b.ContentTypes
+	if len(types) == 0 {
+		types = DefaultBodyContentTypes
+	}
+	for _, t := range types {
+		if (strings.HasSuffix(t, "/") && strings.HasPrefix(mediaType, strings.ToLower(t))) || strings.EqualFold(mediaType, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// replayBody returns the captured part of the request body followed by the rest
+type replayBody struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyWriter copies the first bytes of the response body
+type bodyWriter struct {
+	gin.ResponseWriter
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (w *bodyWriter) Write(p []byte) (int, error) {
+	w.capture(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// capture copies the bytes within the limit
+func (w *bodyWriter) capture(p []byte) {
+	if room := w.max - w.buf.Len(); room < len(p) {
+		w.truncated = true
+		p = p[:room]
+	}
+	w.buf.Write(p)
+}
+
+// contains reports whether the list contains s
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether the list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package log_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/restoflife/log"
+	"github.com/restoflife/log/logtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGinBodyCapture(t *testing.T) {
+	lg, rec := logtest.New(zapcore.DebugLevel)
+	engine := logtest.NewGin(log.WithConfig(lg, log.ConfigGin{BodyCapture: &log.BodyCaptureConfig{
+		Request:  true,
+		Response: true,
+		MaxSize:  8,
+		Paths:    []string{"/users/:id", "/fail"},
+		Methods:  []string{"post"},
+	}}))
+	engine.POST("/users/:id", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusOK, "application/json", body)
+	})
+	engine.POST("/other", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	post := func(target, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		return logtest.ServeGin(engine, req)
+	}
+	// The handler still reads the whole request body
+	if res := post("/users/1", "application/json; charset=utf-8", `{"name":"gopher"}`); res.Body.String() != `{"name":"gopher"}` {
+		t.Fatalf("unexpected response %q", res.Body)
+	}
+	e := rec.AssertLogged(t, zapcore.InfoLevel, log.GIN, zap.String("Path", "/users/1"))
+	logtest.AssertField(t, e, zap.String("RequestBody", `{"name":`))
+	logtest.AssertField(t, e, zap.Bool("RequestBodyTruncated", true))
+	logtest.AssertField(t, e, zap.String("ResponseBody", `{"name":`))
+	logtest.AssertField(t, e, zap.Bool("ResponseBodyTruncated", true))
+
+	post("/other", "application/json", "{}")
+	e = rec.AssertLogged(t, zapcore.InfoLevel, log.GIN, zap.String("Path", "/other"))
+	if _, ok := e.ContextMap()["RequestBody"]; ok {
+		t.Fatalf("path not filtered: %v", e.ContextMap())
+	}
+
+	// Only on errors, and only the allowed content types
+	rec.TakeAll()
+	engine = logtest.NewGin(log.WithConfig(lg, log.ConfigGin{BodyCapture: &log.BodyCaptureConfig{Request: true, Response: true, OnlyErrors: true}}))
+	engine.POST("/users/:id", func(c *gin.Context) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid"})
+	})
+	engine.POST("/ok", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	post("/users/1", "application/octet-stream", "binary")
+	e = rec.AssertLogged(t, zapcore.WarnLevel, log.GIN)
+	logtest.AssertField(t, e, zap.String("ResponseBody", `{"error":"invalid"}`))
+	if _, ok := e.ContextMap()["RequestBody"]; ok {
+		t.Fatalf("content type not filtered: %v", e.ContextMap())
+	}
+	post("/ok", "application/json", "{}")
+	if _, ok := rec.AssertLogged(t, zapcore.InfoLevel, log.GIN).ContextMap()["ResponseBody"]; ok {
+		t.Fatal("body logged without error")
+	}
+}
@@ -0,0 +1,202 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 23:30
+ * @FilePath: log//gin_format.go
+ */
+
+package log
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"strings"
+	"sync"
+)
+
+// LogFormatter returns the fields of the access log entry of a request
+type LogFormatter func(params FormatterParams) []zap.Field
+
+// Names of the formatter presets, see FormatterPreset
+const (
+	FormatterMinimal  = "minimal"
+	FormatterDefault  = "default"
+	FormatterFull     = "full"
+	FormatterECS      = "ecs"
+	FormatterCombined = "combined"
+)
+
+// formatterPresets are the built-in formatters by name
+var formatterPresets = map[string]LogFormatter{
+	FormatterMinimal:  minimalFormatter,
+	FormatterDefault:  defaultFormatter,
+	FormatterFull:     fullFormatter,
+	FormatterECS:      ecsFormatter,
+	FormatterCombined: combinedFormatter,
+}
+
+// FormatterPreset returns the built-in formatter of the name: minimal, default,
+// full, ecs or combined, nil for an unknown name
+func FormatterPreset(name string) LogFormatter {
+	return formatterPresets[strings.ToLower(name)]
+}
+
+// minimalFormatter logs the path, status, method and latency
+func minimalFormatter(p FormatterParams) []zap.Field {
+	return []zap.Field{
+		zap.String("Path", p.Path),
+		zap.Int("Code", p.StatusCode),
+		zap.String("Method", p.Method),
+		zap.String("Latency", p.Latency.String()),
+	}
+}
+
+// defaultFormatter logs the path, status, method, user agent and latency
+func defaultFormatter(p FormatterParams) []zap.Field {
+	return []zap.Field{
+		zap.String("Path", p.Path),
+		zap.Int("Code", p.StatusCode),
+		zap.String("Method", p.Method),
+		zap.String("User-Agent", p.Request.UserAgent()),
+		zap.String("Latency", p.Latency.String()),
+	}
+}
+
+// fullFormatter logs every parameter
+func fullFormatter(p FormatterParams) []zap.Field {
+	fields := append(defaultFormatter(p),
+		zap.String("ClientIP", p.ClientIP),
+		zap.Int("BodySize", p.BodySize),
+		zap.String("Referer", p.Request.Referer()),
+		zap.String("Proto", p.Request.Proto),
+	)
+	if p.ErrorMessage != "" {
+		fields = append(fields, zap.String("Error", p.ErrorMessage))
+	}
+	if len(p.Keys) > 0 {
+		fields = append(fields, zap.Any("Keys", p.Keys))
+	}
+	return fields
+}
+
+// ecsFormatter logs the parameters with the names of the Elastic Common Schema
+func ecsFormatter(p FormatterParams) []zap.Field {
+	fields := []zap.Field{
+		zap.String("url.original", p.Path),
+		zap.Int("http.response.status_code", p.StatusCode),
+		zap.String("http.request.method", p.Method),
+		zap.String("user_agent.original", p.Request.UserAgent()),
+		zap.Int64("event.duration", p.Latency.Nanoseconds()),
+		zap.String("client.ip", p.ClientIP),
+		zap.Int("http.response.body.bytes", p.BodySize),
+		zap.String("http.version", strings.TrimPrefix(p.Request.Proto, "HTTP/")),
+	}
+	if ref := p.Request.Referer(); ref != "" {
+		fields = append(fields, zap.String("http.request.referrer", ref))
+	}
+	if p.ErrorMessage != "" {
+		fields = append(fields, zap.String("error.message", p.ErrorMessage))
+	}
+	return fields
+}
+
+// combinedFormatter logs the line of the Apache combined log format
+func combinedFormatter(p FormatterParams) []zap.Field {
+	return []zap.Field{zap.String("Combined", CombinedLogLine(p))}
+}
+
+// CombinedLogLine returns the request in the Apache combined log format, without line ending:
+//
+//	%h - %u [%t] "%r" %>s %b "%{Referer}i" "%{User-agent}i"
+func CombinedLogLine(p FormatterParams) string {
+	return fmt.Sprintf(`%s "%s" "%s"`, CommonLogLine(p), logItem(p.Request.Referer()), logItem(p.Request.UserAgent()))
+}
+
+// CommonLogLine returns the request in the Apache common log format, without line ending:
+//
+//	%h - %u [%t] "%r" %>s %b
+//
+// The request line holds the URI as received, the values sent by the client
+// are escaped as Apache does so that they cannot forge fields or lines.
+func CommonLogLine(p FormatterParams) string {
+	user := ""
+	if p.Request.URL != nil && p.Request.URL.User != nil {
+		user = p.Request.URL.User.Username()
+	} else if name, _, ok := p.Request.BasicAuth(); ok {
+		user = name
+	}
+	size := "-"
+	if p.BodySize > 0 {
+		size = fmt.Sprint(p.BodySize)
+	}
+	uri := p.Request.RequestURI
+	if uri == "" && p.Request.URL != nil {
+		uri = p.Request.URL.RequestURI()
+	}
+	return fmt.Sprintf(`%s - %s [%s] "%s %s %s" %d %s`,
+		p.ClientIP, logItem(user), p.TimeStamp.Format("02/Jan/2006:15:04:05 -0700"),
+		logItem(p.Method), logItem(uri), logItem(p.Request.Proto), p.StatusCode, size)
+}
+
+// logItem escapes a value of an Apache log line like ap_escape_logitem: quotes
+// and backslashes are escaped with a backslash, control and non-ASCII bytes as
+// \xhh. Empty values are written as "-".
+func logItem(s string) string {
+	if s == "" {
+		return "-"
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == '\b':
+			b.WriteString(`\b`)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c == '\v':
+			b.WriteString(`\v`)
+		case c < 0x20 || c > 0x7e:
+			_, _ = fmt.Fprintf(&b, `\x%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// selectFields keeps the fields named in keep, all of them when keep is empty
+func selectFields(fields []zap.Field, keep map[string]struct{}) []zap.Field {
+	if len(keep) == 0 {
+		return fields
+	}
+	out := fields[:0]
+	for _, f := range fields {
+		if _, ok := keep[f.Key]; ok {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// accessWriter writes the access log lines to the Output of ConfigGin, one
+// write per line so that concurrent requests do not interleave
+type accessWriter struct {
+	mu   sync.Mutex
+	w    io.Writer
+	line func(params FormatterParams) string
+}
+
+// write writes the line of the request, errors of the writer are ignored
+func (a *accessWriter) write(p FormatterParams) {
+	line := a.line(p) + "\n"
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, _ = io.WriteString(a.w, line)
+}
@@ -0,0 +1,109 @@
+package log_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/restoflife/log"
+	"github.com/restoflife/log/logtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGinFormatter(t *testing.T) {
+	logged := func(conf log.ConfigGin) logtest.Entry {
+		t.Helper()
+		lg, rec := logtest.New(zapcore.DebugLevel)
+		engine := logtest.NewGin(log.WithConfig(lg, conf))
+		engine.GET("/users", func(c *gin.Context) {
+			c.Set("user", "u1")
+			c.String(http.StatusOK, "hello")
+		})
+		req := httptest.NewRequest(http.MethodGet, "/users?page=2", nil)
+		req.Header.Set("User-Agent", "curl/8.0")
+		req.Header.Set("Referer", "https://example.com/")
+		req.RemoteAddr = "192.0.2.1:1234"
+		logtest.ServeGin(engine, req)
+		return rec.AssertLogged(t, zapcore.InfoLevel, log.GIN)
+	}
+
+	e := logged(log.ConfigGin{Formatter: log.FormatterPreset(log.FormatterFull)})
+	logtest.AssertField(t, e, zap.String("ClientIP", "192.0.2.1"))
+	logtest.AssertField(t, e, zap.Int("BodySize", 5))
+	logtest.AssertField(t, e, zap.Any("Keys", map[string]interface{}{"user": "u1"}))
+
+	e = logged(log.ConfigGin{Formatter: log.FormatterPreset(log.FormatterECS)})
+	logtest.AssertField(t, e, zap.String("url.original", "/users?page=2"))
+	logtest.AssertField(t, e, zap.Int("http.response.status_code", 200))
+
+	e = logged(log.ConfigGin{Formatter: log.FormatterPreset(log.FormatterCombined)})
+	line, _ := e.ContextMap()["Combined"].(string)
+	if !strings.HasPrefix(line, "192.0.2.1 - - [") || !strings.HasSuffix(line, `] "GET /users?page=2 HTTP/1.1" 200 5 "https://example.com/" "curl/8.0"`) {
+		t.Fatalf("unexpected combined line %q", line)
+	}
+
+	// Field selection
+	e = logged(log.ConfigGin{Formatter: log.FormatterPreset("FULL"), Fields: []string{"Path", "Code"}})
+	if m := e.ContextMap(); len(m) != 2 || m["Path"] != "/users?page=2" {
+		t.Fatalf("unexpected fields %v", m)
+	}
+	if log.FormatterPreset("unknown") != nil {
+		t.Fatal("unexpected preset")
+	}
+}
+
+func TestCommonLogLineEscaping(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/a%22%20x%0Afake?q=%22", nil)
+	req.SetBasicAuth("bob\" \n", "secret")
+	req.Header.Set("User-Agent", "evil\" \\ \x01\xff")
+	p := log.FormatterParams{
+		Request:    req,
+		TimeStamp:  time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+		ClientIP:   "192.0.2.1",
+		Method:     http.MethodGet,
+		StatusCode: http.StatusOK,
+		Path:       "/a\" x\nfake?q=\"",
+	}
+	want := `192.0.2.1 - bob\" \n [02/Jan/2024:10:00:00 +0000] "GET /a%22%20x%0Afake?q=%22 HTTP/1.1" 200 - "-" "evil\" \\ \x01\xff"`
+	if line := log.CombinedLogLine(p); line != want {
+		t.Fatalf("unexpected line\n%s\nwant\n%s", line, want)
+	}
+}
+
+func TestGinAccessLog(t *testing.T) {
+	lg, rec := logtest.New(zapcore.DebugLevel)
+	var out strings.Builder
+	engine := logtest.NewGin(log.WithConfig(lg, log.ConfigGin{Output: &out, AccessLog: log.CombinedLogLine, SkipPaths: []string{"/health"}}))
+	engine.GET("/users", func(c *gin.Context) {
+		c.String(http.StatusOK, "hello")
+	})
+	engine.GET("/health", func(c *gin.Context) {})
+	req := httptest.NewRequest(http.MethodGet, "/users?page=2", nil)
+	req.Header.Set("User-Agent", "curl/8.0")
+	req.RemoteAddr = "192.0.2.1:1234"
+	logtest.ServeGin(engine, req)
+	logtest.Get(engine, "/health")
+
+	// The line is written along with the zap entry, skipped requests are left out
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 1 || !strings.HasPrefix(lines[0], "192.0.2.1 - - [") || !strings.HasSuffix(lines[0], `] "GET /users?page=2 HTTP/1.1" 200 5 "-" "curl/8.0"`) {
+		t.Fatalf("unexpected access log %q", out.String())
+	}
+	rec.AssertLogged(t, zapcore.InfoLevel, log.GIN)
+
+	// Instead of the zap entry
+	rec.TakeAll()
+	out.Reset()
+	engine = logtest.NewGin(log.WithConfig(lg, log.ConfigGin{Output: &out, AccessLog: log.CommonLogLine, AccessLogOnly: true}))
+	engine.GET("/missing", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+	logtest.Get(engine, "/missing")
+	if line := out.String(); !strings.HasSuffix(line, `] "GET /missing HTTP/1.1" 404 -`+"\n") {
+		t.Fatalf("unexpected access log %q", line)
+	}
+	rec.AssertCount(t, 0)
+}
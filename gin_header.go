@@ -0,0 +1,112 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/20 01:35
+ * @FilePath: log//gin_header.go
+ */
+
+package log
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"strings"
+)
+
+// DefaultRedactedHeaders are the credential headers always masked, unless HeaderPolicy.NoDefaultRedact is set
+var DefaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+	"X-Csrf-Token",
+}
+
+// redactedValue replaces the values of the redacted headers
+const redactedValue = "***"
+
+// HeaderPolicy selects the headers logged by the access log and the Recovery
+// request dump, and masks the credentials
+type HeaderPolicy struct {
+	// Request are the request headers logged as RequestHeaders, e.g.
+	// X-Forwarded-For, Referer, Content-Type. The Recovery dump keeps only
+	// these headers when set.
+	Request []string
+	// Response are the response headers logged as ResponseHeaders
+	Response []string
+	// Redact are the headers whose values are masked in addition to
+	// DefaultRedactedHeaders.
+	Redact []string
+	// NoDefaultRedact masks only the headers of Redact, the credentials of
+	// DefaultRedactedHeaders are then logged in clear.
+	NoDefaultRedact bool
+}
+
+// defaultHeaderPolicy masks the credentials of the Recovery dump when no policy is given
+var defaultHeaderPolicy = &HeaderPolicy{}
+
+// redacted reports whether the values of the header are masked
+func (p *HeaderPolicy) redacted(name string) bool {
+	return containsFold(p.Redact, name) || (!p.NoDefaultRedact && containsFold(DefaultRedactedHeaders, name))
+}
+
+// fields returns the RequestHeaders and ResponseHeaders fields
+func (p *HeaderPolicy) fields(req, res http.Header) []zap.Field {
+	if p == nil {
+		return nil
+	}
+	var fields []zap.Field
+	if h := p.selected(req, p.Request); len(h) > 0 {
+		fields = append(fields, zap.Object("RequestHeaders", h))
+	}
+	if h := p.selected(res, p.Response); len(h) > 0 {
+		fields = append(fields, zap.Object("ResponseHeaders", h))
+	}
+	return fields
+}
+
+// selected returns the present headers of names, masked when redacted
+func (p *HeaderPolicy) selected(h http.Header, names []string) headerFields {
+	var out headerFields
+	for _, name := range names {
+		values := h.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		value := strings.Join(values, ", ")
+		if p.redacted(name) {
+			value = redactedValue
+		}
+		out = append(out, [2]string{http.CanonicalHeaderKey(name), value})
+	}
+	return out
+}
+
+// sanitize returns a copy of the headers for the Recovery dump, restricted to the
+// Request allowlist when set and with the credentials masked
+func (p *HeaderPolicy) sanitize(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for name, values := range h {
+		if len(p.Request) > 0 && !containsFold(p.Request, name) {
+			continue
+		}
+		if p.redacted(name) {
+			values = []string{redactedValue}
+		}
+		out[name] = values
+	}
+	return out
+}
+
+// headerFields encodes headers as an object in the order given
+type headerFields [][2]string
+
+func (h headerFields) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, kv := range h {
+		enc.AddString(kv[0], kv[1])
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package log_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/restoflife/log"
+	"github.com/restoflife/log/logtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGinHeaders(t *testing.T) {
+	lg, rec := logtest.New(zapcore.DebugLevel)
+	policy := &log.HeaderPolicy{
+		Request:  []string{"X-Forwarded-For", "authorization", "X-Tenant-ID", "Referer"},
+		Response: []string{"Content-Type", "Set-Cookie"},
+	}
+	engine := logtest.NewGin(log.RecoveryWithConfig(lg, log.RecoveryConfig{Headers: policy}), log.WithConfig(lg, log.ConfigGin{Headers: policy}))
+	engine.GET("/me", func(c *gin.Context) {
+		c.SetCookie("session", "secret", 60, "/", "", false, true)
+		c.JSON(http.StatusOK, gin.H{})
+	})
+	engine.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Add("X-Forwarded-For", "192.0.2.1")
+	req.Header.Add("X-Forwarded-For", "198.51.100.7")
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Tenant-ID", "t1")
+	logtest.ServeGin(engine, req)
+	e := rec.AssertLogged(t, zapcore.InfoLevel, log.GIN)
+	logtest.AssertField(t, e, zap.Any("RequestHeaders", map[string]interface{}{
+		"X-Forwarded-For": "192.0.2.1, 198.51.100.7",
+		"Authorization":   "***",
+		"X-Tenant-Id":     "t1",
+	}))
+	logtest.AssertField(t, e, zap.Any("ResponseHeaders", map[string]interface{}{
+		"Content-Type": "application/json; charset=utf-8",
+		"Set-Cookie":   "***",
+	}))
+
+	// The dump of Recovery follows the policy, default policies mask the credentials
+	req = httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Tenant-ID", "t1")
+	req.Header.Set("X-Other", "dropped")
+	logtest.ServeGin(engine, req)
+	dump := rec.AssertLogged(t, zapcore.ErrorLevel, "[Recovery]").ContextMap()["Request"].(string)
+	if !strings.Contains(dump, "Authorization: ***") || !strings.Contains(dump, "X-Tenant-Id: t1") || strings.Contains(dump, "X-Other") {
+		t.Fatalf("unexpected dump %q", dump)
+	}
+
+	lg, rec = logtest.New(zapcore.DebugLevel)
+	engine = logtest.NewGin(log.Recovery(lg))
+	engine.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	logtest.ServeGin(engine, req)
+	dump = rec.AssertLogged(t, zapcore.ErrorLevel, "[Recovery]").ContextMap()["Request"].(string)
+	if !strings.Contains(dump, "Authorization: ***") || !strings.Contains(dump, "X-Other: dropped") || strings.Contains(dump, "secret") {
+		t.Fatalf("unexpected dump %q", dump)
+	}
+}
+
+func TestHeaderPolicyRedact(t *testing.T) {
+	names := []string{"Cookie", "X-Session", "X-Tenant-ID"}
+	cases := []struct {
+		policy log.HeaderPolicy
+		want   map[string]interface{}
+	}{
+		{log.HeaderPolicy{Request: names}, map[string]interface{}{"Cookie": "***", "X-Session": "s1", "X-Tenant-Id": "t1"}},
+		// Redact adds to the defaults
+		{log.HeaderPolicy{Request: names, Redact: []string{"x-session"}}, map[string]interface{}{"Cookie": "***", "X-Session": "***", "X-Tenant-Id": "t1"}},
+		{log.HeaderPolicy{Request: names, Redact: []string{"X-Session"}, NoDefaultRedact: true}, map[string]interface{}{"Cookie": "c1", "X-Session": "***", "X-Tenant-Id": "t1"}},
+	}
+	for _, tc := range cases {
+		lg, rec := logtest.New(zapcore.DebugLevel)
+		engine := logtest.NewGin(log.WithConfig(lg, log.ConfigGin{Headers: &tc.policy}))
+		engine.GET("/", func(c *gin.Context) {})
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Cookie", "c1")
+		req.Header.Set("X-Session", "s1")
+		req.Header.Set("X-Tenant-ID", "t1")
+		logtest.ServeGin(engine, req)
+		e := rec.AssertLogged(t, zapcore.InfoLevel, log.GIN)
+		logtest.AssertField(t, e, zap.Any("RequestHeaders", tc.want))
+	}
+}
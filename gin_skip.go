@@ -0,0 +1,177 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/20 00:20
+ * @FilePath: log//gin_skip.go
+ */
+
+package log
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// RequestMatcher matches requests of the access log, the conditions that are set must all match
+type RequestMatcher struct {
+	// PathPrefix matches the paths starting with it, e.g. /debug/
+	PathPrefix string
+	// PathGlob matches the path with path.Match, e.g. /health/*
+	PathGlob string
+	// PathRegex matches the path with a regular expression
+	PathRegex string
+	// Route matches the route template as registered, e.g. /users/:id
+	Route string
+	// Methods are the HTTP methods matched
+	Methods []string
+	// StatusMin and StatusMax bound the status matched, 0 for no bound
+	StatusMin, StatusMax int
+	// UserAgent matches the user agents containing it, ignoring case, e.g. kube-probe
+	UserAgent string
+
+	regex *regexp.Regexp
+}
+
+// compile compiles the regular expression, it panics when it is invalid so
+// that a bad pattern of the configuration fails at startup
+func (m *RequestMatcher) compile() {
+	if m.PathRegex != "" && m.regex == nil {
+		re, err := regexp.Compile(m.PathRegex)
+		if err != nil {
+			panic(fmt.Sprintf("log: invalid PathRegex %q: %v", m.PathRegex, err))
+		}
+		m.regex = re
+	}
+}
+
+// empty reports whether no condition is set
+func (m *RequestMatcher) empty() bool {
+	return m.PathPrefix == "" && m.PathGlob == "" && m.PathRegex == "" && m.Route == "" &&
+		len(m.Methods) == 0 && m.StatusMin == 0 && m.StatusMax == 0 && m.UserAgent == ""
+}
+
+// needsStatus reports whether the matcher depends on the response
+func (m *RequestMatcher) needsStatus() bool {
+	return m.StatusMin > 0 || m.StatusMax > 0
+}
+
+// Match reports whether the handled request matches, an empty matcher
+// matches nothing. It panics when PathRegex is invalid.
+func (m *RequestMatcher) Match(c *gin.Context) bool {
+	if m.empty() {
+		return false
+	}
+	p := c.Request.URL.Path
+	if m.PathPrefix != "" && !strings.HasPrefix(p, m.PathPrefix) {
+		return false
+	}
+	if m.PathGlob != "" {
+		if ok, _ := path.Match(m.PathGlob, p); !ok {
+			return false
+		}
+	}
+	if m.PathRegex != "" {
+		// Matchers of the middleware are compiled once, see newAccessFilter
+		m.compile()
+		if !m.regex.MatchString(p) {
+			return false
+		}
+	}
+	if m.Route != "" && m.Route != c.FullPath() {
+		return false
+	}
+	if len(m.Methods) > 0 && !containsFold(m.Methods, c.Request.Method) {
+		return false
+	}
+	status := c.Writer.Status()
+	if (m.StatusMin > 0 && status < m.StatusMin) || (m.StatusMax > 0 && status > m.StatusMax) {
+		return false
+	}
+	if m.UserAgent != "" && !strings.Contains(strings.ToLower(c.Request.UserAgent()), strings.ToLower(m.UserAgent)) {
+		return false
+	}
+	return true
+}
+
+// SampleRule logs the given rate of the matching requests, a rule without
+// conditions matches every request, e.g. a default rate after the other rules
+type SampleRule struct {
+	RequestMatcher
+	// Rate is the share of the requests logged, from 0 to 1
+	Rate float64
+}
+
+// accessFilter decides which requests the access log skips
+type accessFilter struct {
+	skip     []RequestMatcher
+	sampling []SampleRule
+}
+
+// newAccessFilter Create the filter of the config, the regular expressions are compiled once
+func newAccessFilter(conf ConfigGin) *accessFilter {
+	f := &accessFilter{
+		skip:     append([]RequestMatcher(nil), conf.SkipRules...),
+		sampling: append([]SampleRule(nil), conf.Sampling...),
+	}
+	for i := range f.skip {
+		f.skip[i].compile()
+	}
+	for i := range f.sampling {
+		f.sampling[i].compile()
+	}
+	return f
+}
+
+// match reports whether the sampling rule applies to the request
+func (r *SampleRule) match(c *gin.Context) bool {
+	return r.empty() || r.Match(c)
+}
+
+// early reports whether the request is dropped whatever its response, before
+// the handler runs so that its bodies are not captured. The rules depending on
+// the status are left to drop, roll is the draw of the sampling of the request
+// and the sampling rules are left out when sampling is false.
+func (f *accessFilter) early(c *gin.Context, roll float64, sampling bool) bool {
+	for i := range f.skip {
+		if m := &f.skip[i]; !m.needsStatus() && m.Match(c) {
+			return true
+		}
+	}
+	if !sampling {
+		return false
+	}
+	// The first matching rule decides, unknown until the response when it depends on the status
+	for i := range f.sampling {
+		r := &f.sampling[i]
+		if r.needsStatus() {
+			return false
+		}
+		if r.match(c) {
+			return roll >= r.Rate
+		}
+	}
+	return false
+}
+
+// drop reports whether the handled request is not logged, sampling never drops
+// the entries of keep, the requests logged at Warn or above or with errors
+func (f *accessFilter) drop(c *gin.Context, keep bool, roll float64) bool {
+	for i := range f.skip {
+		if f.skip[i].Match(c) {
+			return true
+		}
+	}
+	if keep {
+		return false
+	}
+	// The first matching rule decides
+	for i := range f.sampling {
+		if r := &f.sampling[i]; r.match(c) {
+			return roll >= r.Rate
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package log_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/restoflife/log"
+	"github.com/restoflife/log/logtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGinSkipRules(t *testing.T) {
+	lg, rec := logtest.New(zapcore.DebugLevel)
+	engine := logtest.NewGin(log.WithConfig(lg, log.ConfigGin{
+		SkipRules: []log.RequestMatcher{
+			{PathPrefix: "/debug/"},
+			{PathGlob: "/health/*"},
+			{PathRegex: `^/v\d+/ping$`},
+			{Route: "/users/:id", Methods: []string{"HEAD"}},
+			{UserAgent: "kube-probe"},
+			{PathPrefix: "/static/", StatusMin: 200, StatusMax: 399},
+		},
+		Sampling: []log.SampleRule{
+			{RequestMatcher: log.RequestMatcher{Route: "/metrics"}, Rate: 0},
+		},
+	}))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	engine.GET("/debug/vars", ok)
+	engine.GET("/health/:check", ok)
+	engine.GET("/v2/ping", ok)
+	engine.Handle(http.MethodHead, "/users/:id", ok)
+	engine.GET("/users/:id", ok)
+	engine.GET("/static/*file", func(c *gin.Context) {
+		if c.Param("file") == "/missing" {
+			c.Status(http.StatusNotFound)
+		}
+	})
+	engine.GET("/metrics", func(c *gin.Context) {
+		if c.Query("fail") != "" {
+			c.Status(http.StatusInternalServerError)
+		}
+	})
+
+	for _, target := range []string{"/debug/vars", "/health/db", "/v2/ping", "/static/app.js", "/metrics"} {
+		logtest.Get(engine, target)
+	}
+	logtest.ServeGin(engine, httptest.NewRequest(http.MethodHead, "/users/1", nil))
+	req := httptest.NewRequest(http.MethodGet, "/users/2", nil)
+	req.Header.Set("User-Agent", "kube-probe/1.27")
+	logtest.ServeGin(engine, req)
+	if n := rec.Len(); n != 0 {
+		t.Fatalf("%d entries not skipped: %v", n, rec.All())
+	}
+
+	logtest.Get(engine, "/users/1")
+	logtest.Get(engine, "/static/missing")
+	// Sampling never drops errors
+	logtest.Get(engine, "/metrics?fail=1")
+	rec.AssertCount(t, 3)
+	rec.AssertLogged(t, zapcore.ErrorLevel, log.GIN, zap.String("Path", "/metrics?fail=1"))
+}
+
+func TestGinSampleDefault(t *testing.T) {
+	lg, rec := logtest.New(zapcore.DebugLevel)
+	engine := logtest.NewGin(log.WithConfig(lg, log.ConfigGin{
+		SkipRules: []log.RequestMatcher{{PathPrefix: "/static/"}},
+		Sampling: []log.SampleRule{
+			{RequestMatcher: log.RequestMatcher{Route: "/orders"}, Rate: 1},
+			// A rule without conditions is the rate of the other requests
+			{Rate: 0},
+		},
+		BodyCapture: &log.BodyCaptureConfig{Request: true},
+	}))
+	handler := func(c *gin.Context) {
+		_, _ = io.ReadAll(c.Request.Body)
+		if c.Query("fail") != "" {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusOK)
+	}
+	engine.POST("/orders", handler)
+	engine.POST("/users", handler)
+	engine.POST("/static/*file", handler)
+
+	post := func(target string) {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(`{"id":1}`))
+		req.Header.Set("Content-Type", "application/json")
+		logtest.ServeGin(engine, req)
+	}
+	for _, target := range []string{"/orders", "/users", "/static/app.js", "/users?fail=1"} {
+		post(target)
+	}
+	rec.AssertCount(t, 2)
+	rec.AssertLogged(t, zapcore.InfoLevel, log.GIN, zap.String("Path", "/orders"), zap.ByteString("RequestBody", []byte(`{"id":1}`)))
+	// The bodies of the requests dropped before the handler are not captured,
+	// the errors are logged all the same
+	e := rec.AssertLogged(t, zapcore.ErrorLevel, log.GIN, zap.String("Path", "/users?fail=1"))
+	if _, ok := e.ContextMap()["RequestBody"]; ok {
+		t.Error("the body of a request sampled out was captured")
+	}
+}
+
+func TestGinSkipRulesInvalidRegex(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("an invalid PathRegex does not panic")
+		}
+	}()
+	log.WithConfig(zap.NewNop(), log.ConfigGin{SkipRules: []log.RequestMatcher{{PathRegex: "("}}})
+}
@@ -0,0 +1,75 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/20 00:45
+ * @FilePath: log//gin_slow.go
+ */
+
+package log
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+// slowThreshold returns the slow threshold of the route, 0 when none
+func (conf *ConfigGin) slowThreshold(c *gin.Context) time.Duration {
+	if d, ok := conf.SlowRoutes[c.FullPath()]; ok {
+		return d
+	}
+	return conf.SlowThreshold
+}
+
+// serverTiming returns the Server-Timing header of a request which took d
+func serverTiming(d, threshold time.Duration) string {
+	h := fmt.Sprintf("app;dur=%.3f", float64(d)/float64(time.Millisecond))
+	if threshold > 0 && d > threshold {
+		h += fmt.Sprintf(", slow;desc=\"threshold\";dur=%.3f", float64(threshold)/float64(time.Millisecond))
+	}
+	return h
+}
+
+// timingWriter adds the Server-Timing header when the response starts
+type timingWriter struct {
+	gin.ResponseWriter
+	start     time.Time
+	threshold time.Duration
+	done      bool
+}
+
+// newTimingWriter wraps the writer of the context
+func newTimingWriter(c *gin.Context, start time.Time, threshold time.Duration) *timingWriter {
+	w := &timingWriter{ResponseWriter: c.Writer, start: start, threshold: threshold}
+	c.Writer = w
+	return w
+}
+
+// header sets the header once, before the status is written
+func (w *timingWriter) header() {
+	if w.done || w.ResponseWriter.Written() {
+		return
+	}
+	w.done = true
+	w.Header().Add("Server-Timing", serverTiming(time.Since(w.start), w.threshold))
+}
+
+func (w *timingWriter) WriteHeaderNow() {
+	w.header()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *timingWriter) Write(p []byte) (int, error) {
+	w.header()
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *timingWriter) WriteString(s string) (int, error) {
+	w.header()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *timingWriter) Flush() {
+	w.header()
+	w.ResponseWriter.Flush()
+}
@@ -0,0 +1,45 @@
+package log_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/restoflife/log"
+	"github.com/restoflife/log/logtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGinSlowRequests(t *testing.T) {
+	lg, rec := logtest.New(zapcore.DebugLevel)
+	engine := logtest.NewGin(log.WithConfig(lg, log.ConfigGin{
+		SlowThreshold: 5 * time.Millisecond,
+		SlowRoutes:    map[string]time.Duration{"/reports/:id": time.Hour},
+		ServerTiming:  true,
+	}))
+	sleep := func(c *gin.Context) {
+		time.Sleep(10 * time.Millisecond)
+		c.String(http.StatusOK, "done")
+	}
+	engine.GET("/slow", sleep)
+	engine.GET("/reports/:id", sleep)
+	engine.GET("/empty", func(c *gin.Context) {})
+
+	res := logtest.Get(engine, "/slow")
+	e := rec.AssertLogged(t, zapcore.WarnLevel, log.GIN, zap.Bool("slow", true), zap.String("SlowThreshold", "5ms"))
+	logtest.AssertHasField(t, e, "Latency")
+	if h := res.Header().Get("Server-Timing"); !strings.HasPrefix(h, "app;dur=") || !strings.HasSuffix(h, `, slow;desc="threshold";dur=5.000`) {
+		t.Fatalf("unexpected Server-Timing %q", h)
+	}
+
+	res = logtest.Get(engine, "/reports/1")
+	rec.AssertLogged(t, zapcore.InfoLevel, log.GIN, zap.String("Path", "/reports/1"))
+	if h := res.Header().Get("Server-Timing"); !strings.HasPrefix(h, "app;dur=") || strings.Contains(h, "slow") {
+		t.Fatalf("unexpected Server-Timing %q", h)
+	}
+	if h := logtest.Get(engine, "/empty").Header().Get("Server-Timing"); !strings.HasPrefix(h, "app;dur=") {
+		t.Fatalf("missing Server-Timing without body: %q", h)
+	}
+}
@@ -0,0 +1,124 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/20 02:10
+ * @FilePath: log//gin_stack.go
+ */
+
+package log
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"runtime"
+	"strings"
+)
+
+const (
+	// fingerprintFrames is the number of application frames of the fingerprint
+	fingerprintFrames = 5
+	// maxFrames bounds the structured stack, the raw stack keeps all the frames
+	maxFrames = 32
+	// stackBufSize is the size of the pooled buffers of the goroutine dumps
+	stackBufSize = 64 << 10
+	// maxStackSize bounds the buffer of the goroutine dumps
+	maxStackSize = 64 << 20
+)
+
+// frameFilters are the function prefixes of the frames left out of the structured stack
+var frameFilters = []string{
+	"runtime.",
+	"net/http.",
+	"github.com/gin-gonic/gin.",
+	"github.com/restoflife/log.",
+}
+
+// StackFrame is a frame of the stack of a panic
+type StackFrame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+func (f StackFrame) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("Function", f.Function)
+	enc.AddString("File", f.File)
+	enc.AddInt("Line", f.Line)
+	return nil
+}
+
+// stackFrames encodes frames as an array
+type stackFrames []StackFrame
+
+func (s stackFrames) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, f := range s {
+		if err := enc.AppendObject(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// callerFrames returns at most maxFrames application frames of the calling
+// goroutine from the top, the runtime, net/http, gin and this package are left out
+func callerFrames() []StackFrame {
+	// The filtered frames of the panic are above the application frames
+	pcs := make([]uintptr, 2*maxFrames)
+	pcs = pcs[:runtime.Callers(2, pcs)]
+	var frames []StackFrame
+	it := runtime.CallersFrames(pcs)
+	for len(frames) < maxFrames {
+		f, more := it.Next()
+		if f.Function != "" && !filteredFrame(f.Function) {
+			frames = append(frames, StackFrame{Function: f.Function, File: f.File, Line: f.Line})
+		}
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+// filteredFrame reports whether the function is left out of the structured stack
+func filteredFrame(function string) bool {
+	for _, prefix := range frameFilters {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// StackFingerprint returns a hash of the type of the panic value and the
+// functions of the top frames, stable across line changes, to group the
+// panics of the same code path
+func StackFingerprint(err interface{}, frames []StackFrame) string {
+	if len(frames) > fingerprintFrames {
+		frames = frames[:fingerprintFrames]
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%T\n", err)
+	for _, f := range frames {
+		h.Write([]byte(f.Function))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// stackDump returns the stack of the calling goroutine, or of all goroutines,
+// growing the pooled buffer until the dump fits
+func stackDump(all bool) string {
+	pooled := stackPool.Get().([]byte)
+	buf := pooled
+	for {
+		n := runtime.Stack(buf[:cap(buf)], all)
+		if n < cap(buf) || cap(buf) >= maxStackSize {
+			// The grown buffers are left to the GC so that the pool keeps small buffers
+			stackPool.Put(pooled[:0])
+			return string(buf[:n])
+		}
+		buf = make([]byte, 2*cap(buf))
+	}
+}
@@ -0,0 +1,80 @@
+package log_test
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/restoflife/log"
+	"github.com/restoflife/log/logtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"strings"
+	"testing"
+)
+
+// panicDeep panics below depth frames of recursion
+func panicDeep(depth int) {
+	if depth == 0 {
+		panic("deep")
+	}
+	panicDeep(depth - 1)
+}
+
+func TestRecoveryStack(t *testing.T) {
+	lg, rec := logtest.New(zapcore.DebugLevel)
+	engine := logtest.NewGin(log.RecoveryWithConfig(lg, log.RecoveryConfig{AllGoroutines: true}))
+	engine.GET("/deep", func(c *gin.Context) {
+		panicDeep(2000)
+	})
+
+	logtest.Get(engine, "/deep")
+	fields := rec.AssertLogged(t, zapcore.ErrorLevel, "[Recovery]").ContextMap()
+	frames := fields["Frames"].([]interface{})
+	top := frames[0].(map[string]interface{})
+	if top["Function"] != "github.com/restoflife/log_test.panicDeep" || !strings.HasSuffix(top["File"].(string), "gin_stack_test.go") {
+		t.Fatalf("unexpected top frame %v", top)
+	}
+	for _, f := range frames {
+		fn := f.(map[string]interface{})["Function"].(string)
+		if strings.HasPrefix(fn, "runtime.") || strings.HasPrefix(fn, "github.com/gin-gonic/gin.") {
+			t.Fatalf("frame %s not filtered", fn)
+		}
+	}
+	if len(frames) != 32 {
+		t.Fatalf("%d frames, want 32", len(frames))
+	}
+	if !strings.Contains(fields["Stack"].(string), "log_test.panicDeep") {
+		t.Fatal("unexpected stack")
+	}
+	if _, ok := fields["Goroutines"].(string); !ok {
+		t.Fatal("Goroutines not logged")
+	}
+
+	// Panics of the same code path share the fingerprint
+	fingerprint := fields["Fingerprint"].(string)
+	if len(fingerprint) != 16 {
+		t.Fatalf("unexpected fingerprint %q", fingerprint)
+	}
+	rec.TakeAll()
+	logtest.Get(engine, "/deep")
+	rec.AssertLogged(t, zapcore.ErrorLevel, "[Recovery]", zap.String("Fingerprint", fingerprint))
+	if fingerprint == log.StackFingerprint("deep", []log.StackFrame{{Function: "main.other"}}) {
+		t.Fatal("fingerprint does not depend on the frames")
+	}
+	other := []log.StackFrame{{Function: "main.other"}}
+	if log.StackFingerprint("deep", other) == log.StackFingerprint(errors.New("deep"), other) {
+		t.Fatal("fingerprint does not depend on the type of the panic value")
+	}
+
+	// The goroutine dump is not truncated to the pooled buffer
+	done := make(chan struct{})
+	defer close(done)
+	for i := 0; i < 500; i++ {
+		go func() { <-done }()
+	}
+	rec.TakeAll()
+	logtest.Get(engine, "/deep")
+	goroutines := rec.AssertLogged(t, zapcore.ErrorLevel, "[Recovery]").ContextMap()["Goroutines"].(string)
+	if len(goroutines) <= 64<<10 || strings.Count(goroutines, "goroutine ") < 500 {
+		t.Fatalf("goroutine dump of %d bytes is truncated", len(goroutines))
+	}
+}
@@ -0,0 +1,173 @@
+package log_test
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/restoflife/log"
+	"github.com/restoflife/log/logtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestGinLogger(t *testing.T) {
+	lg, rec := logtest.New(zapcore.DebugLevel)
+	engine := logtest.NewGin(log.Recovery(lg), log.GinLogger(lg))
+	engine.GET("/ok", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	engine.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	engine.GET("/favicon.ico", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	if res := logtest.Get(engine, "/ok?a=1"); res.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", res.Code)
+	}
+	rec.AssertLogged(t, zapcore.InfoLevel, log.GIN, zap.String("Path", "/ok?a=1"), zap.Int("Code", 200), zap.String("Method", "GET"))
+
+	if res := logtest.Get(engine, "/panic"); res.Code != http.StatusInternalServerError {
+		t.Fatalf("unexpected status %d", res.Code)
+	}
+	e := rec.AssertLogged(t, zapcore.ErrorLevel, "[Recovery]", zap.String("Path", "/panic"))
+	if _, ok := e.ContextMap()["Stack"]; !ok {
+		t.Fatalf("no stack %v", e.ContextMap())
+	}
+
+	// The default skip paths are not logged
+	rec.TakeAll()
+	logtest.Get(engine, "/favicon.ico")
+	if n := rec.FilterField(zap.String("Path", "/favicon.ico")).Len(); n != 0 {
+		t.Fatalf("skipped path logged %d times", n)
+	}
+	logtest.Get(engine, "/ok")
+	rec.AssertCount(t, 1)
+}
+
+func TestGinStatusLevels(t *testing.T) {
+	lg, rec := logtest.New(zapcore.DebugLevel)
+	engine := logtest.NewGin(log.GinLogger(lg))
+	engine.GET("/bind", func(c *gin.Context) {
+		_ = c.Error(errors.New("invalid id")).SetType(gin.ErrorTypeBind).SetMeta(map[string]string{"id": "x"})
+		_ = c.Error(errors.New("db down"))
+		c.Status(http.StatusBadRequest)
+	})
+	engine.GET("/fail", func(c *gin.Context) {
+		c.Status(http.StatusServiceUnavailable)
+	})
+	engine.GET("/logged", func(c *gin.Context) {
+		_ = c.Error(errors.New("cache miss"))
+		c.Status(http.StatusOK)
+	})
+
+	logtest.Get(engine, "/bind")
+	e := rec.AssertLogged(t, zapcore.WarnLevel, log.GIN, zap.Int("Code", 400))
+	errs, _ := e.ContextMap()["Errors"].([]interface{})
+	want := []interface{}{
+		map[string]interface{}{"Error": "invalid id", "Type": "bind", "Meta": map[string]string{"id": "x"}},
+		map[string]interface{}{"Error": "db down", "Type": "private"},
+	}
+	if !reflect.DeepEqual(errs, want) {
+		t.Fatalf("unexpected errors %#v", errs)
+	}
+	logtest.Get(engine, "/fail")
+	rec.AssertLogged(t, zapcore.ErrorLevel, log.GIN, zap.Int("Code", 503))
+	// Requests with errors are logged as well
+	logtest.Get(engine, "/logged")
+	logtest.AssertHasField(t, rec.AssertLogged(t, zapcore.InfoLevel, log.GIN, zap.String("Path", "/logged")), "Errors")
+
+	// Every request at Info without rules
+	lg, rec = logtest.New(zapcore.DebugLevel)
+	engine = logtest.NewGin(log.WithConfig(lg, log.ConfigGin{StatusLevels: []log.StatusLevel{}}))
+	logtest.Get(engine, "/missing")
+	rec.AssertLogged(t, zapcore.InfoLevel, log.GIN, zap.Int("Code", 404))
+}
+
+func TestRecoveryConfig(t *testing.T) {
+	lg, rec := logtest.New(zapcore.DebugLevel)
+	engine := logtest.NewGin(log.RequestIDWithConfig(lg, log.RequestIDConfig{Generator: func() string { return "r1" }}),
+		log.RecoveryWithConfig(lg, log.RecoveryConfig{JSON: true, MaxDumpBody: 4, RepanicAbortHandler: true}))
+	engine.POST("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	engine.GET("/gone", func(c *gin.Context) {
+		panic(&net.OpError{Op: "write", Net: "tcp", Err: &os.SyscallError{Syscall: "write", Err: syscall.EPIPE}})
+	})
+	engine.GET("/abort", func(c *gin.Context) {
+		panic(http.ErrAbortHandler)
+	})
+
+	res := logtest.ServeGin(engine, httptest.NewRequest(http.MethodPost, "/panic", strings.NewReader("0123456789")))
+	if res.Code != http.StatusInternalServerError || res.Body.String() != `{"error":"Internal Server Error","request_id":"r1"}` {
+		t.Fatalf("unexpected response %d %q", res.Code, res.Body)
+	}
+	dump := rec.AssertLogged(t, zapcore.ErrorLevel, "[Recovery]").ContextMap()["Request"].(string)
+	if !strings.HasSuffix(dump, "\r\n\r\n0123...(truncated)") {
+		t.Fatalf("unexpected dump %q", dump)
+	}
+
+	rec.TakeAll()
+	logtest.Get(engine, "/gone")
+	e := rec.AssertLogged(t, zapcore.WarnLevel, "[Recovery]", zap.Bool("BrokenPipe", true))
+	if _, ok := e.ContextMap()["Stack"]; ok {
+		t.Fatal("stack logged for a broken pipe")
+	}
+
+	func() {
+		defer func() {
+			if err := recover(); err != http.ErrAbortHandler {
+				t.Fatalf("unexpected panic %v", err)
+			}
+		}()
+		logtest.Get(engine, "/abort")
+	}()
+	rec.AssertCount(t, 1)
+
+	// Custom handler
+	engine = logtest.NewGin(log.RecoveryWithConfig(lg, log.RecoveryConfig{Handler: func(c *gin.Context, err interface{}) {
+		c.String(http.StatusServiceUnavailable, "sorry: %v", err)
+	}}))
+	engine.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	if res = logtest.Get(engine, "/panic"); res.Code != http.StatusServiceUnavailable || res.Body.String() != "sorry: boom" {
+		t.Fatalf("unexpected response %d %q", res.Code, res.Body)
+	}
+
+	// A response already written is only aborted
+	engine = logtest.NewGin(log.RecoveryWithConfig(lg, log.RecoveryConfig{JSON: true}))
+	engine.GET("/partial", func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("boom")
+	})
+	if res = logtest.Get(engine, "/partial"); res.Code != http.StatusOK || res.Body.String() != "partial" {
+		t.Fatalf("unexpected response %d %q", res.Code, res.Body)
+	}
+
+	// The dump uses the body captured by the access log, the handler has read it
+	rec.TakeAll()
+	engine = logtest.NewGin(log.RecoveryWithConfig(lg, log.RecoveryConfig{MaxDumpBody: 6}), log.WithConfig(lg, log.ConfigGin{
+		BodyCapture: &log.BodyCaptureConfig{Request: true},
+	}))
+	engine.POST("/read", func(c *gin.Context) {
+		_, _ = io.ReadAll(c.Request.Body)
+		panic("boom")
+	})
+	req := httptest.NewRequest(http.MethodPost, "/read", strings.NewReader(`{"a":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	logtest.ServeGin(engine, req)
+	dump = rec.AssertLogged(t, zapcore.ErrorLevel, "[Recovery]").ContextMap()["Request"].(string)
+	if !strings.HasSuffix(dump, "\r\n\r\n{\"a\":1...(truncated)") {
+		t.Fatalf("unexpected dump %q", dump)
+	}
+}
@@ -0,0 +1,50 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/20 16:40
+ * @FilePath: log//internal/cli/cli.go
+ */
+
+// Package cli holds the helpers shared by the commands of the module
+package cli
+
+import (
+	"fmt"
+	"github.com/restoflife/log/logfile"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MultiFlag collects a repeated flag
+type MultiFlag []string
+
+func (m *MultiFlag) String() string {
+	return strings.Join(*m, ",")
+}
+
+func (m *MultiFlag) Set(s string) error {
+	*m = append(*m, s)
+	return nil
+}
+
+// ParseTime parses an absolute time or a duration ago
+func ParseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := logfile.ParseTime(s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+// Fatal prints the error after the name of the command and exits
+func Fatal(err error) {
+	_, _ = fmt.Fprintf(os.Stderr, "%s: %v\n", filepath.Base(os.Args[0]), err)
+	os.Exit(1)
+}
@@ -0,0 +1,213 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 21:20
+ * @FilePath: log//levels.go
+ */
+
+package log
+
+import (
+	"errors"
+	"fmt"
+	"github.com/restoflife/log/logfile"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// ComponentLevels holds the level spec of the loggers of a Config such as
+// "info,gin=warn,sql=debug,elastic=error". A bare level is the default, the
+// others apply to the loggers of that name (zap.Logger.Named, sub loggers such
+// as gin.api included) or to the entries with that message prefix such as [GIN].
+// The spec can be changed at runtime.
+type ComponentLevels struct {
+	// base is the default level when the spec has none, Config.Level
+	base zapcore.Level
+	spec atomic.Pointer[levelSpec]
+}
+
+// levelSpec is a parsed level spec
+type levelSpec struct {
+	text       string
+	def        zapcore.Level
+	components map[string]zapcore.Level
+	// min is the lowest level of the spec
+	min zapcore.Level
+}
+
+// newComponentLevels Create the levels of a Config, an invalid spec is
+// returned as error and leaves every component at the base level
+func newComponentLevels(base, spec string) (*ComponentLevels, error) {
+	v := &ComponentLevels{base: zapcore.InfoLevel}
+	if lv, err := zapcore.ParseLevel(base); err == nil {
+		v.base = lv
+	}
+	v.spec.Store(&levelSpec{def: v.base, min: v.base})
+	return v, v.Set(spec)
+}
+
+// Set replaces the spec
+func (v *ComponentLevels) Set(spec string) error {
+	s := &levelSpec{text: spec, def: v.base, components: make(map[string]zapcore.Level)}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, text, ok := strings.Cut(part, "=")
+		if !ok {
+			name, text = "", name
+		}
+		lv, err := zapcore.ParseLevel(strings.TrimSpace(text))
+		if err != nil {
+			return fmt.Errorf("log: level spec %q: %w", spec, err)
+		}
+		if name = strings.ToLower(strings.Trim(strings.TrimSpace(name), "[]")); name == "" {
+			s.def = lv
+		} else {
+			s.components[name] = lv
+		}
+	}
+	s.min = s.def
+	for _, lv := range s.components {
+		if lv < s.min {
+			s.min = lv
+		}
+	}
+	v.spec.Store(s)
+	return nil
+}
+
+// String returns the spec with the default level first
+func (v *ComponentLevels) String() string {
+	s := v.spec.Load()
+	parts := make([]string, 0, len(s.components)+1)
+	parts = append(parts, s.def.String())
+	names := make([]string, 0, len(s.components))
+	for name := range s.components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		parts = append(parts, name+"="+s.components[name].String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// Enabled reports whether the level may be written by any component
+func (v *ComponentLevels) Enabled(lvl zapcore.Level) bool {
+	return lvl >= v.spec.Load().min
+}
+
+// ServeHTTP returns the spec on GET and replaces it with the request body on PUT or POST
+func (v *ComponentLevels) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		body, err := io.ReadAll(io.LimitReader(r.Body, 4096))
+		if err == nil {
+			err = v.Set(strings.TrimSpace(string(body)))
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = fmt.Fprintln(w, v.String())
+}
+
+// level returns the level of the component of the entry, the logger name takes precedence over the prefix
+func (s *levelSpec) level(ent zapcore.Entry) zapcore.Level {
+	if len(s.components) == 0 {
+		return s.def
+	}
+	if name := strings.ToLower(ent.LoggerName); name != "" {
+		// The longest name matches, gin.api before gin
+		for {
+			if lv, ok := s.components[name]; ok {
+				return lv
+			}
+			i := strings.LastIndexByte(name, '.')
+			if i < 0 {
+				break
+			}
+			name = name[:i]
+		}
+	}
+	if prefix := logfile.MessagePrefix(ent.Message); prefix != "" {
+		if lv, ok := s.components[strings.ToLower(strings.Trim(prefix, "[]"))]; ok {
+			return lv
+		}
+	}
+	return s.def
+}
+
+// levelCore drops the entries below the level of their component
+type levelCore struct {
+	zapcore.Core
+	levels *ComponentLevels
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.enabled(ent) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// enabled reports whether the entry is at or above the level of its component
+func (c *levelCore) enabled(ent zapcore.Entry) bool {
+	return ent.Level >= c.levels.spec.Load().level(ent) && c.Core.Enabled(ent.Level)
+}
+
+// SetLevels replaces the level spec of the loggers of the Config, see ComponentLevels
+func (l *Config) SetLevels(spec string) error {
+	if l.levels == nil {
+		v, err := newComponentLevels(l.Level, spec)
+		if err != nil {
+			return err
+		}
+		l.levels = v
+	} else if err := l.levels.Set(spec); err != nil {
+		return err
+	}
+	l.Levels = spec
+	return nil
+}
+
+// ComponentLevels returns the levels of the loggers of the Config, nil before the first logger is created
+func (l *Config) ComponentLevels() *ComponentLevels {
+	return l.levels
+}
+
+// SetLevels replaces the level spec of the default logger, see ComponentLevels
+func SetLevels(spec string) error {
+	if levels == nil {
+		return errors.New("log: the default logger is not created")
+	}
+	return levels.Set(spec)
+}
+
+// LevelsHandler returns an http.Handler reading and changing the level spec of the default logger
+func LevelsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if levels == nil {
+			http.Error(w, "the default logger is not created", http.StatusServiceUnavailable)
+			return
+		}
+		levels.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,98 @@
+package log
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestComponentLevels(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "app.log")
+	c := &Config{
+		Level:    "info",
+		Filename: name,
+		Console:  "fatal",
+		Levels:   "gin=warn,sql=debug,elastic=error",
+	}
+	lg := c.NewLogger()
+	lg.Info(GIN, zap.String("Path", "/hidden"))
+	lg.Warn(GIN, zap.String("Path", "/gin-warn"))
+	lg.Debug(SQL, zap.String("Path", "/sql-debug"))
+	lg.Warn(ELASTIC, zap.String("Path", "/hidden"))
+	lg.Debug("hidden")
+	lg.Info("started")
+	lg.Named("gin").Named("api").Info("hidden")
+	lg.Named("SQL").Debug("by name")
+
+	// Runtime change through the handler
+	srv := httptest.NewServer(c.ComponentLevels())
+	defer srv.Close()
+	res, err := http.Post(srv.URL, "text/plain", strings.NewReader("warn,gin=debug"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusOK || c.ComponentLevels().String() != "warn,gin=debug" {
+		t.Fatalf("status %d, spec %s", res.StatusCode, c.ComponentLevels())
+	}
+	lg.Debug(GIN, zap.String("Path", "/gin-debug"))
+	lg.Info("hidden")
+	if err = c.SetLevels("gin=bogus"); err == nil {
+		t.Fatal("expected an error for an invalid level")
+	}
+
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(b)
+	for _, s := range []string{"/gin-warn", "/sql-debug", "started", "by name", "/gin-debug"} {
+		if !strings.Contains(got, s) {
+			t.Errorf("missing %q:\n%s", s, got)
+		}
+	}
+	if strings.Contains(got, "hidden") {
+		t.Errorf("unexpected entries:\n%s", got)
+	}
+}
+
+func TestComponentLevelsInvalid(t *testing.T) {
+	dir := t.TempDir()
+	c := &Config{
+		Level:    "warn",
+		Filename: filepath.Join(dir, "app.log"),
+		Console:  "fatal",
+		Levels:   "gin=warning",
+	}
+	if _, err := c.Build(); err == nil || !strings.Contains(err.Error(), "warning") {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// A spec set at runtime which cannot be parsed keeps the previous one
+	r := NewRingBuffer(10)
+	c = &Config{
+		Level:    "warn",
+		Filename: filepath.Join(dir, "app.log"),
+		Console:  "fatal",
+		Levels:   "gin=info",
+	}
+	lg := c.NewLogger().WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, &levelCore{Core: r.Core(zapcore.DebugLevel), levels: c.ComponentLevels()})
+	}))
+	if err := c.ComponentLevels().Set("gin=bogus"); err == nil {
+		t.Fatal("invalid spec accepted")
+	}
+	lg.Debug(GIN, zap.String("Path", "/hidden"))
+	lg.Info(GIN, zap.String("Path", "/gin-info"))
+	if got := r.Entries(RingFilter{}); len(got) != 1 || got[0].Fields["Path"] != "/gin-info" {
+		t.Fatalf("unexpected entries %+v", got)
+	}
+	if spec := c.ComponentLevels().String(); spec != "warn,gin=info" {
+		t.Fatalf("unexpected spec %s", spec)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
 	"os"
 	"runtime"
 	"time"
@@ -23,44 +22,145 @@ type Config struct {
 	MaxAge int `json:"max_age"`
 	// 控制台输出等级
 	Console string `json:"console"`
-}
-
-var logger *zap.Logger
+	// 内存中保留的最近日志条数, 0 表示不保留
+	RingSize int `json:"ring_size"`
+	// 内存日志记录级别, 默认与 Level 相同
+	RingLevel string `json:"ring_level"`
+	// 日志采样, 为空表示不采样
+	Sampling *SamplingConfig `json:"sampling"`
+	// 日志文件加密, 为空表示不加密
+	Encryption *EncryptionConfig `json:"encryption"`
+	// 日志文件哈希链, 用于审计日志防篡改, 为空表示不启用
+	Chain *ChainConfig `json:"chain"`
+	// 按消息前缀、日志名称或字段值写入各自的日志文件, 未匹配的写入 Filename
+	Routes []*RouteConfig `json:"routes"`
+	// 按组件设置日志级别, 如 "info,gin=warn,sql=debug", 按日志名称或消息前缀匹配, 可在运行时修改
+	Levels string `json:"levels"`
+	// 服务名称, 设置 Service、Env、Version 或 Labels 后每条日志都带有这些字段以及 hostname、pid 和构建的 revision
+	Service string `json:"service"`
+	// 运行环境, 如 prod、staging
+	Env string `json:"env"`
+	// 服务版本, 默认读取构建信息中的版本
+	Version string `json:"version"`
+	// 自定义标签
+	Labels map[string]string `json:"labels"`
+	// 日志文件格式: console (默认)、json、gcp、ecs、aws, 云平台格式使用各自的字段名
+	Format string `json:"format"`
+	// Google Cloud 项目 ID, gcp 格式下 trace_id 写为 projects/<ID>/traces/<trace_id> 以关联 Cloud Trace, 为空时不关联
+	GCPProject string `json:"gcp_project"`
+
+	// ring keeps the recent entries when RingSize is set
+	ring *RingBuffer
+	// file is the writer of Filename, shared by every logger of this Config
+	file zapcore.WriteSyncer
+	// levels is the level spec of the files, shared by every logger of this Config
+	levels *ComponentLevels
+}
+
+var (
+	logger *zap.Logger
+	// ring is the RingBuffer of the default logger
+	ring *RingBuffer
+	// levels is the level spec of the default logger
+	levels *ComponentLevels
+)
 
 // New Create a new logger using the configuration
 func New(g *Config) {
 	// Create a new logger using the configuration
 	logger = g.NewLogger()
+	ring = g.ring
+	levels = g.levels
 }
 
-// NewLogger Create a new logger with the given configuration
+// NewLogger Create a new logger with the given configuration, it panics when
+// the log files cannot be set up, see Build
 func (l *Config) NewLogger() *zap.Logger {
-	encoder := createFileEncoder()
+	lg, err := l.Build()
+	if err != nil {
+		panic(err)
+	}
+	return lg
+}
+
+// Build Create a new logger with the given configuration, an error is returned
+// when the log files cannot be set up, e.g. an invalid Levels spec, an invalid
+// Encryption or Chain key or a hash chain which cannot be continued
+func (l *Config) Build() (*zap.Logger, error) {
+	encoder := l.newFileEncoder()
 
 	consoleEncoder := createConsoleEncoder()
 
 	cores := make([]zapcore.Core, 0)
 
+	if l.levels == nil {
+		levels, err := newComponentLevels(l.Level, l.Levels)
+		if err != nil {
+			return nil, err
+		}
+		l.levels = levels
+	}
+
+	if l.file == nil {
+		w, err := l.newFileWriter()
+		if err != nil {
+			return nil, err
+		}
+		l.file = newCountingWriter("file:"+l.Filename, zapcore.AddSync(w))
+	}
+
+	fileCore := l.newFileCore(
+		encoder,
+		l.file,
+		l.levels,
+	)
+	fileCore = &levelCore{Core: fileCore, levels: l.levels}
+	if len(l.Routes) > 0 {
+		var err error
+		if fileCore, err = l.newRouteCore(encoder, fileCore); err != nil {
+			return nil, err
+		}
+	}
+
 	cores = append(
 		cores,
-		zapcore.NewCore(
-			encoder,
-			zapcore.AddSync(&lumberjack.Logger{
-				Filename:   l.Filename,
-				MaxSize:    l.MaxSize,
-				MaxBackups: l.MaxBackups,
-				MaxAge:     l.MaxAge,
-				LocalTime:  true,
-			}),
-			createLevelEnablerFunc(l.Level),
-		),
+		fileCore,
 		zapcore.NewCore(
 			consoleEncoder,
-			zapcore.Lock(os.Stderr),
+			newCountingWriter("console", zapcore.Lock(os.Stderr)),
 			createLevelEnablerFunc(l.Console),
 		),
 	)
-	return zap.New(zapcore.NewTee(cores...))
+	// Keep the recent entries in memory, the buffer is shared by every logger of this Config
+	if l.RingSize > 0 {
+		if l.ring == nil {
+			l.ring = NewRingBuffer(l.RingSize)
+		}
+		// An invalid RingLevel falls back to Level, then to info
+		var enab zapcore.LevelEnabler = zapcore.InfoLevel
+		for _, level := range []string{l.RingLevel, l.Level} {
+			if f := createLevelEnablerFunc(level); level != "" && f != nil {
+				enab = f
+				break
+			}
+		}
+		cores = append(cores, l.ring.Core(enab))
+	}
+	core := zapcore.NewTee(cores...)
+	// Drop repeated entries, the decisions are counted in the metrics
+	if s := l.Sampling; s != nil {
+		tick := s.Tick
+		if tick <= 0 {
+			tick = time.Second
+		}
+		core = zapcore.NewSamplerWithOptions(core, tick, s.Initial, s.Thereafter, zapcore.SamplerHook(countSampling))
+	}
+	return zap.New(core, zap.Hooks(countEntry), zap.Fields(l.staticFields()...)), nil
+}
+
+// Ring returns the RingBuffer of the loggers created by this Config, nil when RingSize is not set
+func (l *Config) Ring() *RingBuffer {
+	return l.ring
 }
 
 // Logger This function returns a pointer to the logger
@@ -68,6 +168,11 @@ func Logger() *zap.Logger {
 	return logger
 }
 
+// Ring returns the RingBuffer of the default logger, nil when RingSize is not set
+func Ring() *RingBuffer {
+	return ring
+}
+
 // This function takes a string as input and returns a zap.LevelEnablerFunc
 func createLevelEnablerFunc(input string) zap.LevelEnablerFunc {
 	var lv = new(zapcore.Level)
@@ -95,22 +200,6 @@ func createConsoleEncoder() zapcore.Encoder {
 	return zapcore.NewConsoleEncoder(encoderConfig)
 }
 
-// Create a new encoder configuration
-func createFileEncoder() zapcore.Encoder {
-
-	encoderConfig := zap.NewProductionEncoderConfig()
-
-	encoderConfig.EncodeTime = timeEncoder
-
-	encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
-
-	encoderConfig.EncodeDuration = zapcore.SecondsDurationEncoder
-
-	encoderConfig.EncodeCaller = zapcore.ShortCallerEncoder
-
-	return zapcore.NewConsoleEncoder(encoderConfig)
-}
-
 // This function takes a time.Time object and an encoder of type zapcore.PrimitiveArrayEncoder and appends the time in RFC3339Nano format to the encoder
 func timeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
 
@@ -0,0 +1,267 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 19:10
+ * @FilePath: log//logfile/chain.go
+ */
+
+package logfile
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// In hash chained files every entry ends with "\t#chain:<seq>:<hash>" where
+// hash is the hex encoded HMAC-SHA256 (SHA-256 without key) of the previous
+// hash, the sequence number as a big endian uint64 and the entry without its
+// line ending. Every file starts with "#chain anchor <seq> <hash>", the last
+// sequence number and hash of the previous file, so that the chain goes on
+// across rotations.
+//
+// The anchor line is not authenticated: it is checked against the end of the
+// previous file, so the anchor of the oldest file verified can be rewritten
+// along with its entries when the chain is not keyed. Entries cut off the end
+// of the newest file cannot be detected either, since nothing follows them;
+// keep the last sequence number and hash elsewhere to detect it.
+const (
+	// chainSuffix starts the chain column of an entry
+	chainSuffix = "\t#chain:"
+	// anchorPrefix starts the anchor line of a file
+	anchorPrefix = "#chain anchor "
+)
+
+// chainPattern matches the chain column at the end of an entry
+var chainPattern = regexp.MustCompile(`\t#chain:(\d+):([0-9a-f]{64})$`)
+
+// Chain computes the hash chain of the entries written to a file
+type Chain struct {
+	key []byte
+	// Seq is the sequence number of the last entry
+	Seq uint64
+	// Prev is the hash of the last entry, nil at the start of the chain
+	Prev []byte
+}
+
+// NewChain Create a Chain, entries are authenticated with HMAC-SHA256 when key is set
+func NewChain(key []byte) *Chain {
+	return &Chain{key: key}
+}
+
+// newHash returns the hash function of the chain
+func (c *Chain) newHash() hash.Hash {
+	if len(c.key) > 0 {
+		return hmac.New(sha256.New, c.key)
+	}
+	return sha256.New()
+}
+
+// sum computes the hash of an entry
+func (c *Chain) sum(prev []byte, seq uint64, data []byte) []byte {
+	h := c.newHash()
+	h.Write(prev)
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], seq)
+	h.Write(b[:])
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// Anchor returns the anchor line starting a new file
+func (c *Chain) Anchor() []byte {
+	prev := "-"
+	if c.Prev != nil {
+		prev = hex.EncodeToString(c.Prev)
+	}
+	return []byte(fmt.Sprintf("%s%d %s\n", anchorPrefix, c.Seq, prev))
+}
+
+// Append chains an entry and returns it with its chain column
+func (c *Chain) Append(p []byte) []byte {
+	data := p
+	if n := len(data); n > 0 && data[n-1] == '\n' {
+		data = data[:n-1]
+	}
+	c.Seq++
+	c.Prev = c.sum(c.Prev, c.Seq, data)
+	out := make([]byte, 0, len(data)+len(chainSuffix)+90)
+	out = append(out, data...)
+	out = append(out, chainSuffix...)
+	out = strconv.AppendUint(out, c.Seq, 10)
+	out = append(out, ':')
+	out = hex.AppendEncode(out, c.Prev)
+	return append(out, '\n')
+}
+
+// Resume continues the chain after the last entry read from r
+func (c *Chain) Resume(r io.Reader) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64<<10), 16<<20)
+	for sc.Scan() {
+		line := sc.Text()
+		if seq, prev, ok := parseAnchor(line); ok {
+			c.Seq, c.Prev = seq, prev
+			continue
+		}
+		if m := chainPattern.FindStringSubmatch(line); m != nil {
+			c.Seq, _ = strconv.ParseUint(m[1], 10, 64)
+			c.Prev, _ = hex.DecodeString(m[2])
+		}
+	}
+	return sc.Err()
+}
+
+// parseAnchor parses the anchor line of a file
+func parseAnchor(line string) (uint64, []byte, bool) {
+	if !strings.HasPrefix(line, anchorPrefix) {
+		return 0, nil, false
+	}
+	fields := strings.Fields(line[len(anchorPrefix):])
+	if len(fields) != 2 {
+		return 0, nil, false
+	}
+	seq, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return 0, nil, false
+	}
+	if fields[1] == "-" {
+		return seq, nil, true
+	}
+	prev, err := hex.DecodeString(fields[1])
+	if err != nil {
+		return 0, nil, false
+	}
+	return seq, prev, true
+}
+
+// stripChain removes the chain column from a line
+func stripChain(line string) string {
+	if i := strings.LastIndex(line, chainSuffix); i >= 0 && chainPattern.MatchString(line[i:]) {
+		return line[:i]
+	}
+	return line
+}
+
+// isAnchor reports whether the line is the anchor line of a chained file
+func isAnchor(line string) bool {
+	return strings.HasPrefix(line, anchorPrefix)
+}
+
+// Problem is an integrity problem found by a Verifier
+type Problem struct {
+	// File is the name of the file
+	File string
+	// Line is the line number the problem was found at
+	Line int
+	// Seq is the sequence number of the entry, if known
+	Seq uint64
+	// Message describes the problem
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s:%d: seq %d: %s", p.File, p.Line, p.Seq, p.Message)
+}
+
+// Verifier checks the hash chain of a sequence of files, oldest first
+type Verifier struct {
+	chain *Chain
+	// started is set once the first anchor has been read
+	started bool
+	// Start is the sequence number the oldest file continues, entries before
+	// it were in files removed by the retention, e.g. MaxBackups or MaxAge
+	Start uint64
+	// Entries is the number of entries verified
+	Entries int
+	// Problems are the deleted, reordered or edited entries found
+	Problems []Problem
+}
+
+// NewVerifier Create a Verifier of the chain written with key, see NewChain
+func NewVerifier(key []byte) *Verifier {
+	return &Verifier{chain: NewChain(key)}
+}
+
+// Verify checks the next file of the chain
+func (v *Verifier) Verify(name string, r io.Reader) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64<<10), 16<<20)
+	var (
+		record  []string
+		lineNo  int
+		problem = func(seq uint64, format string, args ...interface{}) {
+			v.Problems = append(v.Problems, Problem{File: name, Line: lineNo, Seq: seq, Message: fmt.Sprintf(format, args...)})
+		}
+	)
+	for sc.Scan() {
+		lineNo++
+		line := sc.Text()
+		if lineNo == 1 {
+			seq, prev, ok := parseAnchor(line)
+			switch {
+			case !ok:
+				problem(0, "missing chain anchor, the start of the file was removed or the file is not chained")
+			case !v.started:
+				// The oldest file sets the start of the chain, older files are pruned by the retention
+				v.chain.Seq, v.chain.Prev = seq, prev
+				v.Start = seq
+			case seq != v.chain.Seq || !hmac.Equal(prev, v.chain.Prev):
+				problem(seq, "anchor does not match the end of the previous file (seq %d), entries or files are missing", v.chain.Seq)
+				v.chain.Seq, v.chain.Prev = seq, prev
+			}
+			v.started = true
+			if ok {
+				continue
+			}
+		}
+		m := chainPattern.FindStringSubmatch(line)
+		if m == nil {
+			// Multi-line entry such as a stack trace
+			record = append(record, line)
+			continue
+		}
+		record = append(record, line[:len(line)-len(m[0])])
+		data := strings.Join(record, "\n")
+		record = record[:0]
+		seq, _ := strconv.ParseUint(m[1], 10, 64)
+		got, _ := hex.DecodeString(m[2])
+		v.Entries++
+		switch want := v.chain.Seq + 1; {
+		case seq > want:
+			problem(seq, "%d entries deleted before this entry", seq-want)
+		case seq < want:
+			problem(seq, "entry out of order or replayed, expected seq %d", want)
+		case !hmac.Equal(got, v.chain.sum(v.chain.Prev, seq, []byte(data))):
+			problem(seq, "hash mismatch, the entry was edited")
+		}
+		// Resynchronise on the written hash so that one problem is reported once
+		v.chain.Seq, v.chain.Prev = seq, got
+	}
+	if len(record) > 0 {
+		problem(v.chain.Seq, "%d trailing lines are not chained", len(record))
+	}
+	return sc.Err()
+}
+
+// ParseMACKey decodes a base64 or hex encoded HMAC key of any length
+func ParseMACKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if key, err := hex.DecodeString(s); err == nil && len(key) > 0 {
+		return key, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(key) == 0 {
+		return nil, fmt.Errorf("logfile: invalid HMAC key")
+	}
+	return key, nil
+}
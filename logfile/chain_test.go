@@ -0,0 +1,87 @@
+package logfile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// chained writes the lines as a chained file
+func chained(c *Chain, lines ...string) []string {
+	out := []string{strings.TrimSuffix(string(c.Anchor()), "\n")}
+	for _, l := range lines {
+		out = append(out, strings.TrimSuffix(string(c.Append([]byte(l+"\n"))), "\n"))
+	}
+	return out
+}
+
+func TestChain(t *testing.T) {
+	key := []byte("secret")
+	c := NewChain(key)
+	first := chained(c, "2024-01-02T10:00:00Z\tINFO\tone", "2024-01-02T10:00:01Z\tERROR\ttwo\ngoroutine 1 [running]:", "2024-01-02T10:00:02Z\tINFO\tthree")
+	second := chained(c, "2024-01-02T10:00:03Z\tINFO\tfour", "2024-01-02T10:00:04Z\tINFO\tfive")
+
+	verify := func(key []byte, files ...[]string) *Verifier {
+		t.Helper()
+		v := NewVerifier(key)
+		for i, f := range files {
+			if err := v.Verify(string(rune('a'+i)), strings.NewReader(strings.Join(f, "\n")+"\n")); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return v
+	}
+	if v := verify(key, first, second); len(v.Problems) != 0 || v.Entries != 5 {
+		t.Fatalf("unexpected result %d entries %v", v.Entries, v.Problems)
+	}
+	if v := verify([]byte("other"), first); len(v.Problems) != 3 {
+		t.Fatalf("wrong key: %v", v.Problems)
+	}
+
+	remove := func(lines []string, i int) []string {
+		return append(append([]string(nil), lines[:i]...), lines[i+1:]...)
+	}
+	edited := append([]string(nil), first...)
+	edited[1] = strings.Replace(edited[1], "one", "uno", 1)
+	reordered := []string{first[0], first[1], first[3], first[2]}
+	cases := []struct {
+		name  string
+		files [][]string
+		want  string
+	}{
+		{"deleted", [][]string{remove(first, 1), second}, "1 entries deleted"},
+		{"edited", [][]string{edited, second}, "edited"},
+		{"reordered", [][]string{reordered, second}, "out of order"},
+		{"tail removed", [][]string{first[:3], second}, "anchor does not match"},
+		{"anchor removed", [][]string{first[1:]}, "missing chain anchor"},
+	}
+	// Files removed by the retention are not a problem
+	if v := verify(key, second); len(v.Problems) != 0 || v.Start != 3 {
+		t.Fatalf("retention: start %d, problems %v", v.Start, v.Problems)
+	}
+	for _, tc := range cases {
+		v := verify(key, tc.files...)
+		found := false
+		for _, p := range v.Problems {
+			found = found || strings.Contains(p.Message, tc.want)
+		}
+		if !found {
+			t.Errorf("%s: unexpected problems %v", tc.name, v.Problems)
+		}
+	}
+
+	// Resume continues after the last entry of a file
+	r := NewChain(key)
+	if err := r.Resume(strings.NewReader(strings.Join(first, "\n"))); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(r.Anchor(), []byte(second[0]+"\n")) {
+		t.Fatalf("resumed anchor %q, want %q", r.Anchor(), second[0])
+	}
+
+	// The reader hides the chain
+	entries := readAll(t, strings.NewReader(strings.Join(first, "\n")+"\n"))
+	if len(entries) != 3 || entries[0].Message != "one" || entries[1].Stack != "goroutine 1 [running]:" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+}
@@ -0,0 +1,285 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 17:20
+ * @FilePath: log//logfile/crypt.go
+ */
+
+package logfile
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Encrypted files start with a header made of Magic, the length of the key ID
+// as a big endian uint16 and the key ID. Each write of the logger follows as a
+// record made of the length of the sealed data as a big endian uint32, a 12
+// byte nonce and the AES-GCM sealed entry. The file name and the key ID are
+// not secret, the nonces are random.
+//
+// The records of a file are numbered from 0 and the number is authenticated as
+// the additional data of its record, so that dropped, reordered or repeated
+// records fail to decrypt. Records cut off the end of a file, or a whole file
+// replaced by another one written with the same key, cannot be detected this
+// way, see the hash chain of the Chain for the former. A record torn by a
+// failed write ends the file for the reader, the logger continues in a new file.
+const (
+	// Magic starts every encrypted log file
+	Magic = "RLOGENC1"
+	// maxRecord bounds the size of a record so that a corrupt length cannot exhaust memory
+	maxRecord = 64 << 20
+)
+
+var (
+	// ErrEncrypted is returned when an encrypted file is opened without keys
+	ErrEncrypted = errors.New("logfile: file is encrypted, keys are required")
+	// ErrUnknownKey is returned when the key of an encrypted file is not in the keyring
+	ErrUnknownKey = errors.New("logfile: unknown key")
+)
+
+// Keyring maps key IDs to AES keys
+type Keyring map[string][]byte
+
+// ParseKey decodes a hex or base64 encoded AES-128, AES-192 or AES-256 key.
+// Hex is tried first: a 32 digit hex AES-128 key is valid base64 as well, and
+// would decode to another, 24 byte key.
+func ParseKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if validKeySize(len(s) / 2) {
+		if key, err := hex.DecodeString(s); err == nil {
+			return key, nil
+		}
+	}
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("logfile: invalid key: %w", err)
+	}
+	if !validKeySize(len(key)) {
+		return nil, fmt.Errorf("logfile: invalid key size %d", len(key))
+	}
+	return key, nil
+}
+
+// validKeySize reports whether n is an AES key size
+func validKeySize(n int) bool {
+	return n == 16 || n == 24 || n == 32
+}
+
+// ParseKeyring reads a keyring, one "id key" or "id=key" per line, # starts a comment
+func ParseKeyring(r io.Reader) (Keyring, error) {
+	keys := make(Keyring)
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Base64 keys end with '=', so white space is tried first
+		id, k, ok := strings.Cut(line, " ")
+		if !ok {
+			if id, k, ok = strings.Cut(line, "="); !ok {
+				return nil, fmt.Errorf("logfile: keyring line %d: missing key", n)
+			}
+		}
+		key, err := ParseKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("logfile: keyring line %d: %w", n, err)
+		}
+		keys[strings.TrimSpace(id)] = key
+	}
+	return keys, sc.Err()
+}
+
+// ReadKeyring reads a keyring file, see ParseKeyring
+func ReadKeyring(name string) (Keyring, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseKeyring(f)
+}
+
+// Header returns the header of an encrypted file
+func Header(keyID string) []byte {
+	b := make([]byte, 0, len(Magic)+2+len(keyID))
+	b = append(b, Magic...)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(keyID)))
+	return append(b, keyID...)
+}
+
+// Sealer encrypts the records of an encrypted file, a Sealer is used for a
+// single file and is not safe for concurrent use
+type Sealer struct {
+	aead cipher.AEAD
+	// seq is the number of the next record
+	seq uint64
+}
+
+// NewSealer Create a Sealer using the AES key
+func NewSealer(key []byte) (*Sealer, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Sealer{aead: aead}, nil
+}
+
+// Seal returns the next record of the file holding the encrypted data
+func (s *Sealer) Seal(p []byte) ([]byte, error) {
+	n := s.aead.NonceSize()
+	rec := make([]byte, 4+n, 4+n+len(p)+s.aead.Overhead())
+	if _, err := rand.Read(rec[4:]); err != nil {
+		return nil, err
+	}
+	rec = s.aead.Seal(rec, rec[4:4+n], p, recordData(s.seq))
+	s.seq++
+	binary.BigEndian.PutUint32(rec, uint32(len(rec)-4))
+	return rec, nil
+}
+
+// recordData returns the additional data of the record with the number
+func recordData(seq uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, seq)
+}
+
+// newAEAD Create the AES-GCM cipher of the key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// IsEncrypted reports whether the data starts with the header of an encrypted file
+func IsEncrypted(prefix []byte) bool {
+	return bytes.HasPrefix(prefix, []byte(Magic))
+}
+
+// DecryptReader decrypts an encrypted file record by record, so that it can be read while it is written
+type DecryptReader struct {
+	r     io.Reader
+	keys  Keyring
+	aead  cipher.AEAD
+	keyID string
+	buf   []byte
+	// seq is the number of the next record
+	seq uint64
+}
+
+// NewDecryptReader Create a reader returning the plain text of the encrypted file read from r
+func NewDecryptReader(r io.Reader, keys Keyring) *DecryptReader {
+	return &DecryptReader{r: r, keys: keys}
+}
+
+// KeyID returns the key ID of the file, once the header has been read
+func (d *DecryptReader) KeyID() string {
+	return d.keyID
+}
+
+func (d *DecryptReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if err := d.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+// next reads the header or the next record
+func (d *DecryptReader) next() error {
+	if d.aead == nil {
+		return d.readHeader()
+	}
+	var size [4]byte
+	if _, err := io.ReadFull(d.r, size[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("logfile: truncated record: %w", err)
+		}
+		return err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > maxRecord || int(n) < d.aead.NonceSize()+d.aead.Overhead() {
+		return fmt.Errorf("logfile: invalid record size %d", n)
+	}
+	rec := make([]byte, n)
+	if _, err := io.ReadFull(d.r, rec); err != nil {
+		return fmt.Errorf("logfile: truncated record: %w", err)
+	}
+	ns := d.aead.NonceSize()
+	plain, err := d.aead.Open(rec[ns:ns], rec[:ns], rec[ns:], recordData(d.seq))
+	if err != nil {
+		return fmt.Errorf("logfile: record %d cannot be decrypted with key %q, it was altered, dropped or moved: %w", d.seq, d.keyID, err)
+	}
+	d.seq++
+	d.buf = plain
+	return nil
+}
+
+// readHeader reads the header and selects the key
+func (d *DecryptReader) readHeader() error {
+	id, err := readHeader(d.r)
+	if err != nil {
+		return err
+	}
+	d.keyID = id
+	key, ok := d.keys[d.keyID]
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownKey, d.keyID)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	d.aead = aead
+	return nil
+}
+
+// readHeader reads the header of an encrypted file and returns its key ID
+func readHeader(r io.Reader) (id string, err error) {
+	head := make([]byte, len(Magic)+2)
+	if _, err = io.ReadFull(r, head); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return "", errors.New("logfile: truncated header")
+		}
+		return "", err
+	}
+	if !IsEncrypted(head) {
+		return "", errors.New("logfile: not an encrypted log file")
+	}
+	b := make([]byte, binary.BigEndian.Uint16(head[len(Magic):]))
+	if _, err = io.ReadFull(r, b); err != nil {
+		return "", errors.New("logfile: truncated header")
+	}
+	return string(b), nil
+}
+
+// FileKeyID returns the key ID of an encrypted file, or an empty string for a plain file
+func FileKeyID(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	if head, _ := br.Peek(len(Magic)); !IsEncrypted(head) {
+		return "", nil
+	}
+	id, err := readHeader(br)
+	return id, err
+}
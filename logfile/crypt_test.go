@@ -0,0 +1,71 @@
+package logfile
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParseKey(t *testing.T) {
+	cases := []struct {
+		s    string
+		size int
+	}{
+		// A hex AES-128 key is valid base64 too
+		{"000102030405060708090a0b0c0d0e0f", 16},
+		{"000102030405060708090A0B0C0D0E0F1011121314151617", 24},
+		{strings.Repeat("ab", 32), 32},
+		{base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 16)), 16},
+		{base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)), 32},
+		{"short", 0},
+	}
+	for _, tc := range cases {
+		key, err := ParseKey(tc.s)
+		if tc.size == 0 {
+			if err == nil {
+				t.Errorf("%q: expected an error", tc.s)
+			}
+			continue
+		}
+		if err != nil || len(key) != tc.size {
+			t.Errorf("%q: got %d bytes, %v", tc.s, len(key), err)
+		}
+	}
+	key, _ := ParseKey("000102030405060708090a0b0c0d0e0f")
+	if key[0] != 0 || key[15] != 15 {
+		t.Errorf("unexpected key %x", key)
+	}
+}
+
+func TestSealedRecords(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 16)
+	s, err := NewSealer(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recs [][]byte
+	for _, line := range []string{"a\n", "b\n", "c\n"} {
+		rec, err := s.Seal([]byte(line))
+		if err != nil {
+			t.Fatal(err)
+		}
+		recs = append(recs, rec)
+	}
+	read := func(recs ...[]byte) (string, error) {
+		file := append(Header("k1"), bytes.Join(recs, nil)...)
+		b, err := io.ReadAll(NewDecryptReader(bytes.NewReader(file), Keyring{"k1": key}))
+		return string(b), err
+	}
+	if plain, err := read(recs...); err != nil || plain != "a\nb\nc\n" {
+		t.Fatalf("unexpected plain text %q, %v", plain, err)
+	}
+	// Dropped and reordered records are found
+	if _, err = read(recs[0], recs[2]); err == nil {
+		t.Error("dropped record not detected")
+	}
+	if _, err = read(recs[1], recs[0], recs[2]); err == nil {
+		t.Error("reordered records not detected")
+	}
+}
@@ -0,0 +1,127 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 14:45
+ * @FilePath: log//logfile/files.go
+ */
+
+package logfile
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupTimeFormat is the timestamp lumberjack puts in the name of the rotated files
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// Open opens a log file, gzipped backups are decompressed transparently
+func Open(name string) (io.ReadCloser, error) {
+	return OpenWithKeys(name, nil)
+}
+
+// OpenWithKeys opens a log file like Open, encrypted files are decrypted with the keys
+func OpenWithKeys(name string, keys Keyring) (io.ReadCloser, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	rc := &file{Reader: f, closers: []io.Closer{f}}
+	if strings.HasSuffix(name, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		rc.Reader = zr
+		rc.closers = append(rc.closers, zr)
+	}
+	// Encrypted files are recognised by their header
+	br := bufio.NewReader(rc.Reader)
+	rc.Reader = br
+	if head, _ := br.Peek(len(Magic)); IsEncrypted(head) {
+		if keys == nil {
+			_ = rc.Close()
+			return nil, fmt.Errorf("%s: %w", name, ErrEncrypted)
+		}
+		rc.Reader = NewDecryptReader(br, keys)
+	}
+	return rc, nil
+}
+
+// file closes the readers stacked on a log file
+type file struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (f *file) Close() error {
+	var err error
+	for i := len(f.closers) - 1; i >= 0; i-- {
+		if cerr := f.closers[i].Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Backups returns the rotated backups of the lumberjack file name, oldest first.
+// Backups are named name-2006-01-02T15-04-05.000.ext and may be gzipped.
+func Backups(name string) ([]string, error) {
+	dir := filepath.Dir(name)
+	base := filepath.Base(name)
+	ext := filepath.Ext(base)
+	prefix := base[:len(base)-len(ext)] + "-"
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	type backup struct {
+		name string
+		t    time.Time
+	}
+	var backups []backup
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		fn := e.Name()
+		ts := strings.TrimSuffix(fn, ".gz")
+		if !strings.HasPrefix(ts, prefix) || !strings.HasSuffix(ts, ext) {
+			continue
+		}
+		ts = ts[len(prefix) : len(ts)-len(ext)]
+		t, err := time.Parse(backupTimeFormat, ts)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{name: filepath.Join(dir, fn), t: t})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].t.Before(backups[j].t)
+	})
+	names := make([]string, 0, len(backups))
+	for _, b := range backups {
+		names = append(names, b.name)
+	}
+	return names, nil
+}
+
+// WithBackups returns the rotated backups of the file followed by the file itself
+func WithBackups(name string) ([]string, error) {
+	names, err := Backups(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(name); err == nil {
+		names = append(names, name)
+	}
+	return names, nil
+}
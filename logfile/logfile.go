@@ -0,0 +1,287 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 14:10
+ * @FilePath: log//logfile/logfile.go
+ */
+
+// Package logfile reads the files written by github.com/restoflife/log:
+// console encoded lines made of the time, level, logger name, caller,
+// message and a JSON tail with the context fields, or one JSON object per line
+// for the json, gcp, ecs and aws formats.
+package logfile
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Entry is a parsed log entry
+type Entry struct {
+	// Time is the time the entry was logged
+	Time time.Time
+	// Level is the capital level, e.g. INFO
+	Level string
+	// Logger is the logger name, if any
+	Logger string
+	// Caller is the file and line of the caller, if any
+	Caller string
+	// Message is the log message
+	Message string
+	// Prefix is the message prefix such as [GIN], [SQL], [GORM] or [ELASTIC]
+	Prefix string
+	// Fields are the context fields decoded from the JSON tail, numbers are json.Number
+	Fields map[string]interface{}
+	// Stack is the stack trace following the entry, if any
+	Stack string
+	// Raw is the text of the entry as read from the file
+	Raw string
+}
+
+// timeLayouts are the layouts of the time column, newest first
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000Z0700",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02 15:04:05",
+}
+
+// callerPattern matches the caller column, e.g. log/gin.go:120
+var callerPattern = regexp.MustCompile(`^\S+\.go:\d+$`)
+
+// ErrNotEntry is returned by ParseLine for lines which do not start a log entry
+var ErrNotEntry = errors.New("logfile: not a log entry")
+
+// ParseTime parses the time column of a log entry
+func ParseTime(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("logfile: invalid time %q", s)
+}
+
+// ParseLine parses a single line written by the console or a JSON encoder
+func ParseLine(line string) (Entry, error) {
+	line = stripChain(strings.TrimRight(line, "\r\n"))
+	if strings.HasPrefix(line, "{") {
+		return parseJSONLine(line)
+	}
+	parts := strings.Split(line, "\t")
+	if len(parts) < 3 {
+		return Entry{}, ErrNotEntry
+	}
+	t, err := ParseTime(parts[0])
+	if err != nil {
+		return Entry{}, ErrNotEntry
+	}
+	e := Entry{Time: t, Level: parts[1], Raw: line}
+	rest := parts[2:]
+	// The JSON tail is the last column when the entry has fields
+	if last := rest[len(rest)-1]; len(rest) > 1 && strings.HasPrefix(last, "{") && strings.HasSuffix(last, "}") {
+		fields, err := decodeFields(last)
+		if err == nil {
+			e.Fields = fields
+			rest = rest[:len(rest)-1]
+		}
+	}
+	// What is left is [logger] [caller] message, the message may contain tabs
+	switch {
+	case len(rest) >= 3 && callerPattern.MatchString(rest[1]):
+		e.Logger, e.Caller, rest = rest[0], rest[1], rest[2:]
+	case len(rest) >= 2 && callerPattern.MatchString(rest[0]):
+		e.Caller, rest = rest[0], rest[1:]
+	case len(rest) >= 2 && isLoggerName(rest[0]):
+		e.Logger, rest = rest[0], rest[1:]
+	}
+	e.Message = strings.Join(rest, "\t")
+	e.Prefix = MessagePrefix(e.Message)
+	return e, nil
+}
+
+// jsonKeys are the keys of the columns in the json, gcp, ecs and aws formats
+var jsonKeys = struct {
+	time, level, logger, caller, message, stack []string
+}{
+	time:    []string{"ts", "time", "timestamp", "@timestamp"},
+	level:   []string{"level", "severity", "log.level"},
+	logger:  []string{"logger", "log.logger"},
+	caller:  []string{"caller", "log.origin.file.name"},
+	message: []string{"msg", "message"},
+	stack:   []string{"stacktrace", "stack_trace", "error.stack_trace"},
+}
+
+// parseJSONLine parses an entry written by a JSON encoder
+func parseJSONLine(line string) (Entry, error) {
+	fields, err := decodeFields(line)
+	if err != nil {
+		return Entry{}, ErrNotEntry
+	}
+	take := func(keys []string) string {
+		for _, k := range keys {
+			if v, ok := fields[k].(string); ok {
+				delete(fields, k)
+				return v
+			}
+		}
+		return ""
+	}
+	t, err := ParseTime(take(jsonKeys.time))
+	if err != nil {
+		return Entry{}, ErrNotEntry
+	}
+	e := Entry{Time: t, Raw: line}
+	switch e.Level = strings.ToUpper(take(jsonKeys.level)); e.Level {
+	case "WARNING":
+		e.Level = "WARN"
+	case "CRITICAL", "ALERT", "EMERGENCY":
+		e.Level = "FATAL"
+	}
+	e.Logger = take(jsonKeys.logger)
+	e.Caller = take(jsonKeys.caller)
+	e.Message = take(jsonKeys.message)
+	e.Stack = take(jsonKeys.stack)
+	e.Prefix = MessagePrefix(e.Message)
+	if len(fields) > 0 {
+		e.Fields = fields
+	}
+	return e, nil
+}
+
+// isLoggerName reports whether the column looks like a name given to zap.Logger.Named
+func isLoggerName(s string) bool {
+	if s == "" || strings.HasPrefix(s, "[") {
+		return false
+	}
+	for _, r := range s {
+		if r == ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeFields decodes the JSON tail keeping the numbers as json.Number
+func decodeFields(s string) (map[string]interface{}, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	fields := make(map[string]interface{})
+	if err := dec.Decode(&fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// MessagePrefix returns the leading "[XXX]" tag of a log message, or an empty string
+func MessagePrefix(msg string) string {
+	if !strings.HasPrefix(msg, "[") {
+		return ""
+	}
+	if i := strings.IndexByte(msg, ']'); i > 0 {
+		return msg[:i+1]
+	}
+	return ""
+}
+
+// MatchPrefix reports whether the message prefix matches the given name, e.g. "gin", "GIN" or "[GIN]"
+func MatchPrefix(prefix, name string) bool {
+	return strings.EqualFold(strings.Trim(prefix, "[]"), strings.Trim(name, "[]"))
+}
+
+// Field returns the value of a field, dots select nested objects. The columns
+// time, level, logger, caller, msg and prefix are available as fields too.
+func (e Entry) Field(name string) (interface{}, bool) {
+	switch name {
+	case "time":
+		return e.Time.Format(time.RFC3339Nano), true
+	case "level":
+		return e.Level, true
+	case "logger":
+		return e.Logger, true
+	case "caller":
+		return e.Caller, true
+	case "msg", "message":
+		return e.Message, true
+	case "prefix":
+		return e.Prefix, true
+	}
+	if v, ok := e.Fields[name]; ok {
+		return v, true
+	}
+	var cur interface{} = e.Fields
+	for _, key := range strings.Split(name, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// Reader reads the entries of a log file, joining the stack trace lines to their entry
+type Reader struct {
+	sc      *bufio.Scanner
+	pending *Entry
+}
+
+// NewReader Create a Reader reading the entries from r
+func NewReader(r io.Reader) *Reader {
+	sc := bufio.NewScanner(r)
+	// Recovery entries carry whole request dumps and stacks
+	sc.Buffer(make([]byte, 64<<10), 16<<20)
+	return &Reader{sc: sc}
+}
+
+// Next returns the next entry, or io.EOF at the end of the input
+func (r *Reader) Next() (Entry, error) {
+	for r.sc.Scan() {
+		line := r.sc.Text()
+		// Anchor lines of hash chained files are not entries
+		if isAnchor(line) {
+			continue
+		}
+		e, err := ParseLine(line)
+		if err != nil {
+			// Continuation of the previous entry, e.g. a stack trace, the
+			// chain column ends the last line of a multi-line entry
+			line = stripChain(line)
+			if r.pending != nil {
+				r.pending.Stack = joinLine(r.pending.Stack, line)
+				r.pending.Raw = joinLine(r.pending.Raw, line)
+			}
+			continue
+		}
+		prev := r.pending
+		r.pending = &e
+		if prev != nil {
+			return *prev, nil
+		}
+	}
+	if err := r.sc.Err(); err != nil {
+		return Entry{}, err
+	}
+	if r.pending != nil {
+		e := *r.pending
+		r.pending = nil
+		return e, nil
+	}
+	return Entry{}, io.EOF
+}
+
+// joinLine appends a line to a block of text
+func joinLine(block, line string) string {
+	if block == "" {
+		return line
+	}
+	return block + "\n" + line
+}
@@ -0,0 +1,153 @@
+package logfile
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sample = `2023-10-28T21:11:09.297+0800	INFO	[GIN]	{"Path": "/s", "Code": 200, "Method": "GET", "Latency": "1.5s"}
+2024-01-02T10:00:00+08:00	ERROR	api	log/gin.go:120	[Recovery]	{"Path": "/panic", "Error": "boom"}
+goroutine 1 [running]:
+main.main()
+2024-01-02T10:00:01+08:00	WARN	[GORM]	{"SQL": "SELECT 1", "Rows": 1, "Latency": "250ms"}
+2024-01-02T10:00:02+08:00	INFO	started
+`
+
+func readAll(t *testing.T, r io.Reader) []Entry {
+	t.Helper()
+	var entries []Entry
+	rd := NewReader(r)
+	for {
+		e, err := rd.Next()
+		if errors.Is(err, io.EOF) {
+			return entries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+}
+
+func TestReader(t *testing.T) {
+	entries := readAll(t, strings.NewReader(sample))
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	gin := entries[0]
+	if gin.Level != "INFO" || gin.Prefix != "[GIN]" || gin.Fields["Code"] != json.Number("200") {
+		t.Errorf("unexpected entry %+v", gin)
+	}
+	rec := entries[1]
+	if rec.Logger != "api" || rec.Caller != "log/gin.go:120" || rec.Message != "[Recovery]" {
+		t.Errorf("unexpected entry %+v", rec)
+	}
+	if rec.Stack != "goroutine 1 [running]:\nmain.main()" {
+		t.Errorf("unexpected stack %q", rec.Stack)
+	}
+	if entries[3].Message != "started" || entries[3].Fields != nil {
+		t.Errorf("unexpected entry %+v", entries[3])
+	}
+}
+
+func TestFilter(t *testing.T) {
+	entries := readAll(t, strings.NewReader(sample))
+	match := func(f Filter) []string {
+		var msgs []string
+		for _, e := range entries {
+			if f.Match(e) {
+				msgs = append(msgs, e.Message)
+			}
+		}
+		return msgs
+	}
+	pred := func(expr string) Predicate {
+		p, err := ParsePredicate(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	cases := []struct {
+		filter Filter
+		want   []string
+	}{
+		{Filter{Level: "warn"}, []string{"[Recovery]", "[GORM]"}},
+		{Filter{Prefixes: []string{"gin", "gorm"}}, []string{"[GIN]", "[GORM]"}},
+		{Filter{Predicates: []Predicate{pred("Code>=200")}}, []string{"[GIN]"}},
+		{Filter{Predicates: []Predicate{pred("Latency>1s")}}, []string{"[GIN]"}},
+		{Filter{Predicates: []Predicate{pred("Latency<=250ms")}}, []string{"[GORM]"}},
+		{Filter{Predicates: []Predicate{pred("Path~/pa"), pred("logger=api")}}, []string{"[Recovery]"}},
+	}
+	for _, c := range cases {
+		if got := match(c.filter); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%+v: got %v, want %v", c.filter, got, c.want)
+		}
+	}
+	if _, err := ParsePredicate("Code"); err == nil {
+		t.Error("expected an error for a predicate without operator")
+	}
+}
+
+func TestBackups(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "gin.log")
+	write := func(fn, data string, gz bool) {
+		f, err := os.Create(filepath.Join(dir, fn))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		var w io.Writer = f
+		if gz {
+			zw := gzip.NewWriter(f)
+			defer zw.Close()
+			w = zw
+		}
+		_, _ = io.WriteString(w, data)
+	}
+	write("gin.log", "2024-01-03T00:00:00Z\tINFO\tcurrent\n", false)
+	write("gin-2024-01-02T00-00-00.000.log.gz", "2024-01-02T00:00:00Z\tINFO\tsecond\n", true)
+	write("gin-2024-01-01T00-00-00.000.log", "2024-01-01T00:00:00Z\tINFO\tfirst\n", false)
+	write("other.log", "", false)
+
+	names, err := WithBackups(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgs []string
+	for _, n := range names {
+		f, err := Open(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range readAll(t, f) {
+			msgs = append(msgs, e.Message)
+		}
+		_ = f.Close()
+	}
+	if want := []string{"first", "second", "current"}; !reflect.DeepEqual(msgs, want) {
+		t.Errorf("got %v, want %v", msgs, want)
+	}
+}
+
+func TestParseJSONLine(t *testing.T) {
+	e, err := ParseLine(`{"severity":"WARNING","time":"2024-01-02T10:00:00.5Z","logger":"api","message":"[GIN]","httpRequest":{"status":500}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, _ := e.Field("httpRequest.status")
+	if e.Level != "WARN" || e.Logger != "api" || e.Prefix != "[GIN]" || status != json.Number("500") || e.Time.Nanosecond() != 5e8 {
+		t.Fatalf("unexpected entry %+v", e)
+	}
+	if _, err = ParseLine(`{"not":"an entry"}`); !errors.Is(err, ErrNotEntry) {
+		t.Fatalf("expected ErrNotEntry, got %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 15:05
+ * @FilePath: log//logfile/predicate.go
+ */
+
+package logfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// operators are the comparison operators of a predicate, longest first
+var operators = []string{">=", "<=", "!=", "==", "!~", "=~", ">", "<", "=", "~"}
+
+// Predicate compares a field of an entry with a value, e.g. Code>=500 or Latency>1s
+type Predicate struct {
+	// Field is the field name, see Entry.Field
+	Field string
+	// Op is one of == != > >= < <= ~ (contains) !~ (does not contain)
+	Op string
+	// Value is the right hand side
+	Value string
+}
+
+// ParsePredicate parses an expression such as Code>=500, Latency>1s or Path~/api
+func ParsePredicate(expr string) (Predicate, error) {
+	for i := 0; i < len(expr); i++ {
+		for _, op := range operators {
+			if strings.HasPrefix(expr[i:], op) {
+				p := Predicate{
+					Field: strings.TrimSpace(expr[:i]),
+					Op:    op,
+					Value: strings.TrimSpace(expr[i+len(op):]),
+				}
+				switch p.Op {
+				case "=":
+					p.Op = "=="
+				case "=~":
+					p.Op = "~"
+				}
+				if p.Field == "" {
+					return p, fmt.Errorf("logfile: missing field in %q", expr)
+				}
+				return p, nil
+			}
+		}
+	}
+	return Predicate{}, fmt.Errorf("logfile: missing operator in %q", expr)
+}
+
+// String returns the expression of the predicate
+func (p Predicate) String() string {
+	return p.Field + p.Op + p.Value
+}
+
+// Match reports whether the entry satisfies the predicate, entries without the field never match except for !=
+func (p Predicate) Match(e Entry) bool {
+	v, ok := e.Field(p.Field)
+	if !ok {
+		return p.Op == "!=" || p.Op == "!~"
+	}
+	s := ValueString(v)
+	switch p.Op {
+	case "~":
+		return strings.Contains(s, p.Value)
+	case "!~":
+		return !strings.Contains(s, p.Value)
+	}
+	return compareResult(p.Op, compare(s, p.Value))
+}
+
+// compare compares two values as numbers, durations, times or strings, in that order
+func compare(a, b string) int {
+	if x, err := strconv.ParseFloat(a, 64); err == nil {
+		if y, err := strconv.ParseFloat(b, 64); err == nil {
+			return compareOrdered(x, y)
+		}
+	}
+	if x, err := time.ParseDuration(a); err == nil {
+		if y, err := time.ParseDuration(b); err == nil {
+			return compareOrdered(x, y)
+		}
+	}
+	if x, err := ParseTime(a); err == nil {
+		if y, err := ParseTime(b); err == nil {
+			return x.Compare(y)
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// compareOrdered returns -1, 0 or 1 like strings.Compare
+func compareOrdered[T int64 | float64 | time.Duration](x, y T) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// compareResult maps the result of compare to the operator
+func compareResult(op string, c int) bool {
+	switch op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	}
+	return false
+}
+
+// ValueString formats a field value as plain text, objects and arrays are JSON encoded
+func ValueString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case nil:
+		return ""
+	case map[string]interface{}, []interface{}:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+// levels orders the capital levels written by zapcore.CapitalLevelEncoder
+var levels = map[string]int{"DEBUG": -1, "INFO": 0, "WARN": 1, "ERROR": 2, "DPANIC": 3, "PANIC": 4, "FATAL": 5}
+
+// LevelAtLeast reports whether the level of an entry is at or above minLevel, both case-insensitive
+func LevelAtLeast(level, minLevel string) bool {
+	l, ok := levels[strings.ToUpper(level)]
+	if !ok {
+		return true
+	}
+	m, ok := levels[strings.ToUpper(minLevel)]
+	if !ok {
+		return true
+	}
+	return l >= m
+}
+
+// Filter selects entries, empty values match everything
+type Filter struct {
+	// Since and Until bound the time of the entries
+	Since, Until time.Time
+	// Level is the minimum level, e.g. warn
+	Level string
+	// Prefixes are the accepted message prefixes, e.g. gin or [SQL]
+	Prefixes []string
+	// Predicates must all match
+	Predicates []Predicate
+}
+
+// Match reports whether the entry passes the filter
+func (f Filter) Match(e Entry) bool {
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Time.Before(f.Until) {
+		return false
+	}
+	if f.Level != "" && !LevelAtLeast(e.Level, f.Level) {
+		return false
+	}
+	if len(f.Prefixes) > 0 {
+		ok := false
+		for _, p := range f.Prefixes {
+			if MatchPrefix(e.Prefix, p) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	for _, p := range f.Predicates {
+		if !p.Match(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// Duration converts a field value to a duration, strings use time.ParseDuration
+// and numbers are seconds as written by zapcore.SecondsDurationEncoder
+func Duration(v interface{}) (time.Duration, bool) {
+	switch v := v.(type) {
+	case string:
+		d, err := time.ParseDuration(v)
+		return d, err == nil
+	case json.Number:
+		f, err := v.Float64()
+		return time.Duration(f * float64(time.Second)), err == nil
+	case float64:
+		return time.Duration(v * float64(time.Second)), true
+	}
+	return 0, false
+}
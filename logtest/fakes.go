@@ -0,0 +1,108 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 11:45
+ * @FilePath: log//logtest/fakes.go
+ */
+
+package logtest
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/restoflife/log"
+	glog "gorm.io/gorm/logger"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+	xlog "xorm.io/xorm/log"
+)
+
+// TraceGorm drives the Trace callback of a GORM logger as if the statement ran for elapsed
+func TraceGorm(ctx context.Context, l glog.Interface, sql string, rows int64, elapsed time.Duration, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	l.Trace(ctx, time.Now().Add(-elapsed), func() (string, int64) {
+		return sql, rows
+	}, err)
+}
+
+// AfterXorm drives the BeforeSQL and AfterSQL callbacks of a XORM logger as if the statement ran for elapsed
+func AfterXorm(ctx context.Context, l xlog.SQLLogger, sql string, args []interface{}, elapsed time.Duration, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	lc := xlog.LogContext{
+		Ctx:         ctx,
+		SQL:         sql,
+		Args:        args,
+		ExecuteTime: elapsed,
+		Err:         err,
+	}
+	l.BeforeSQL(lc)
+	l.AfterSQL(lc)
+}
+
+// ElasticResponse describes the fake response handed to ElasticsearchLog.LogRoundTrip
+type ElasticResponse struct {
+	// StatusCode of the response, 0 means the request failed
+	StatusCode int
+	// Body of the response
+	Body string
+	// Duration of the round trip
+	Duration time.Duration
+	// Err returned by the transport
+	Err error
+}
+
+// RoundTripElastic drives ElasticsearchLog.LogRoundTrip with the request and a fake response
+func RoundTripElastic(l *log.ElasticsearchLog, req *http.Request, res ElasticResponse) error {
+	resp := &http.Response{
+		StatusCode: res.StatusCode,
+		Body:       io.NopCloser(strings.NewReader(res.Body)),
+		Request:    req,
+	}
+	if res.StatusCode > 0 {
+		resp.Status = fmt.Sprintf("%d %s", res.StatusCode, http.StatusText(res.StatusCode))
+	}
+	return l.LogRoundTrip(req, resp, res.Err, time.Now().Add(-res.Duration), res.Duration)
+}
+
+// NewElasticRequest Create a request as built by the Elasticsearch client
+func NewElasticRequest(method, target, body string) *http.Request {
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, r)
+	if body != "" {
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(body)), nil
+		}
+	}
+	return req
+}
+
+// NewGin Create a gin engine in test mode using the given middleware
+func NewGin(middleware ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(middleware...)
+	return engine
+}
+
+// ServeGin serves the request with the handler without listening on a port
+func ServeGin(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// Get serves a GET request for target with the handler, see ServeGin
+func Get(h http.Handler, target string) *httptest.ResponseRecorder {
+	return ServeGin(h, httptest.NewRequest(http.MethodGet, target, nil))
+}
@@ -0,0 +1,143 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 11:20
+ * @FilePath: log//logtest/logtest.go
+ */
+
+// Package logtest builds observed loggers and drives the integrations of
+// github.com/restoflife/log without MySQL, Elasticsearch or a listening port.
+package logtest
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Entry is an entry captured by a Recorder
+type Entry = observer.LoggedEntry
+
+// Recorder captures the entries written to an observed logger
+type Recorder struct {
+	*observer.ObservedLogs
+}
+
+// New Create a logger recording every entry at or above the given level
+func New(level zapcore.LevelEnabler) (*zap.Logger, *Recorder) {
+	core, logs := observer.New(level)
+	return zap.New(core), &Recorder{ObservedLogs: logs}
+}
+
+// NewTee Create a logger writing both to the given logger and to a Recorder
+func NewTee(lg *zap.Logger, level zapcore.LevelEnabler) (*zap.Logger, *Recorder) {
+	core, logs := observer.New(level)
+	return lg.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(c, core)
+	})), &Recorder{ObservedLogs: logs}
+}
+
+// Entries returns the captured entries, oldest first
+func (r *Recorder) Entries() []Entry {
+	return r.All()
+}
+
+// Messages returns the messages of the captured entries
+func (r *Recorder) Messages() []string {
+	all := r.All()
+	msgs := make([]string, 0, len(all))
+	for _, e := range all {
+		msgs = append(msgs, e.Message)
+	}
+	return msgs
+}
+
+// Find returns the entries with the given level and message
+func (r *Recorder) Find(level zapcore.Level, msg string) []Entry {
+	return r.FilterLevelExact(level).FilterMessage(msg).All()
+}
+
+// AssertLogged fails the test unless an entry with the level, message and fields was captured.
+// The fields are a subset, other fields of the entry are ignored. The first matching entry is returned.
+func (r *Recorder) AssertLogged(t testing.TB, level zapcore.Level, msg string, fields ...zap.Field) Entry {
+	t.Helper()
+	candidates := r.Find(level, msg)
+	for _, e := range candidates {
+		if diff := FieldsDiff(e, fields...); diff == "" {
+			return e
+		}
+	}
+	if len(candidates) == 0 {
+		t.Errorf("no %s entry %q was logged, got:\n%s", level.CapitalString(), msg, r.dump())
+	} else {
+		t.Errorf("%s entry %q was logged with other fields: %s", level.CapitalString(), msg, FieldsDiff(candidates[0], fields...))
+	}
+	return Entry{}
+}
+
+// AssertNotLogged fails the test if an entry with the message was captured at any level
+func (r *Recorder) AssertNotLogged(t testing.TB, msg string) {
+	t.Helper()
+	if n := r.FilterMessage(msg).Len(); n > 0 {
+		t.Errorf("entry %q was logged %d times", msg, n)
+	}
+}
+
+// AssertCount fails the test unless exactly n entries were captured
+func (r *Recorder) AssertCount(t testing.TB, n int) {
+	t.Helper()
+	if r.Len() != n {
+		t.Errorf("got %d entries, want %d:\n%s", r.Len(), n, r.dump())
+	}
+}
+
+// AssertField fails the test unless the entry has the field with the given value
+func AssertField(t testing.TB, e Entry, f zap.Field) {
+	t.Helper()
+	if diff := FieldsDiff(e, f); diff != "" {
+		t.Errorf("entry %q: %s", e.Message, diff)
+	}
+}
+
+// AssertHasField fails the test unless the entry has a field with the key
+func AssertHasField(t testing.TB, e Entry, key string) interface{} {
+	t.Helper()
+	v, ok := e.ContextMap()[key]
+	if !ok {
+		t.Errorf("entry %q has no field %q: %v", e.Message, key, e.ContextMap())
+	}
+	return v
+}
+
+// FieldsDiff describes the fields missing from the entry or having another value, an empty string means they all match
+func FieldsDiff(e Entry, fields ...zap.Field) string {
+	got := e.ContextMap()
+	want := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(want)
+	}
+	var diffs []string
+	for k, v := range want.Fields {
+		g, ok := got[k]
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("missing %s=%v", k, v))
+		case !reflect.DeepEqual(g, v):
+			diffs = append(diffs, fmt.Sprintf("%s=%v, want %v", k, g, v))
+		}
+	}
+	return strings.Join(diffs, ", ")
+}
+
+// dump formats the captured entries for failure messages
+func (r *Recorder) dump() string {
+	var b strings.Builder
+	for _, e := range r.All() {
+		_, _ = fmt.Fprintf(&b, "\t%s %s %v\n", e.Level.CapitalString(), e.Message, e.ContextMap())
+	}
+	return b.String()
+}
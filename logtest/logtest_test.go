@@ -0,0 +1,82 @@
+package logtest
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/restoflife/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	glog "gorm.io/gorm/logger"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	lg, rec := New(zapcore.DebugLevel)
+	lg.Info("hello", zap.String("k", "v"), zap.Int("n", 1))
+	lg.Debug("debug")
+
+	rec.AssertCount(t, 2)
+	e := rec.AssertLogged(t, zapcore.InfoLevel, "hello", zap.Int("n", 1))
+	AssertField(t, e, zap.String("k", "v"))
+	rec.AssertNotLogged(t, "missing")
+	if diff := FieldsDiff(e, zap.String("k", "x")); diff == "" {
+		t.Error("expected a field difference")
+	}
+}
+
+func TestGormLogger(t *testing.T) {
+	lg, rec := New(zapcore.DebugLevel)
+	gl := log.NewGormLogger(lg).LogMode(glog.Warn)
+	ctx := context.Background()
+
+	TraceGorm(ctx, gl, "SELECT 1", 1, time.Millisecond, nil)
+	TraceGorm(ctx, gl, "SELECT 2", 0, time.Second, nil)
+	TraceGorm(ctx, gl, "SELECT 3", 0, time.Millisecond, errors.New("boom"))
+
+	rec.AssertLogged(t, zapcore.WarnLevel, log.GORM, zap.String("SQL", "SELECT 2"))
+	rec.AssertLogged(t, zapcore.ErrorLevel, log.GORM, zap.String("SQL", "SELECT 3"), zap.String("error", "boom"))
+}
+
+func TestXormLogger(t *testing.T) {
+	lg, rec := New(zapcore.DebugLevel)
+	xl := log.NewXormLogger(lg)
+	ctx := context.Background()
+
+	AfterXorm(ctx, xl, "SELECT * FROM t WHERE id = ?", []interface{}{7}, time.Millisecond, nil)
+	AfterXorm(ctx, xl, "SELECT 1", nil, time.Millisecond, errors.New("boom"))
+
+	rec.AssertLogged(t, zapcore.InfoLevel, log.SQL, zap.String("SQL", "SELECT * FROM t WHERE id = 7"))
+	rec.AssertLogged(t, zapcore.ErrorLevel, log.SQL, zap.String("SQL", "SELECT 1"))
+}
+
+func TestNewGin(t *testing.T) {
+	lg, rec := New(zapcore.DebugLevel)
+	engine := NewGin(func(c *gin.Context) {
+		c.Next()
+		lg.Info("served", zap.Int("Code", c.Writer.Status()))
+	})
+	engine.GET("/ok", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	if res := Get(engine, "/ok"); res.Code != http.StatusOK || res.Body.String() != "ok" {
+		t.Fatalf("unexpected response %d %q", res.Code, res.Body)
+	}
+	rec.AssertLogged(t, zapcore.InfoLevel, "served", zap.Int("Code", 200))
+}
+
+func TestElasticsearchLog(t *testing.T) {
+	lg, rec := New(zapcore.DebugLevel)
+	el := log.NewElasticLogger(lg, true, true)
+
+	req := NewElasticRequest(http.MethodPost, "http://es:9200/idx/_search?q=a%20b", `{"query": {}}`)
+	if err := RoundTripElastic(el, req, ElasticResponse{StatusCode: 200, Body: `{"hits": {}}`, Duration: 3 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	rec.AssertLogged(t, zapcore.InfoLevel, log.ELASTIC, zap.String("Path", "/idx/_search?q=a b"), zap.String("Status", "200 OK"), zap.String("Time", "3ms"))
+	rec.AssertLogged(t, zapcore.InfoLevel, `[ES-REQUEST]  {"query": {}}`)
+	rec.AssertLogged(t, zapcore.InfoLevel, `[ES-RESPONSE] {"hits": {}} `)
+}
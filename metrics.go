@@ -0,0 +1,232 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 13:05
+ * @FilePath: log//metrics.go
+ */
+
+package log
+
+import (
+	"expvar"
+	"fmt"
+	"github.com/restoflife/log/logfile"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SamplingConfig limits the entries written per second, see zapcore.NewSamplerWithOptions
+type SamplingConfig struct {
+	// 每个周期内相同消息先记录的条数
+	Initial int `json:"initial"`
+	// 之后每隔多少条记录一次
+	Thereafter int `json:"thereafter"`
+	// 采样周期, 默认 1 秒
+	Tick time.Duration `json:"tick"`
+}
+
+// EntryCount is the number of entries with the same logger, level and prefix
+type EntryCount struct {
+	Logger string `json:"logger"`
+	Level  string `json:"level"`
+	Prefix string `json:"prefix"`
+	Count  uint64 `json:"count"`
+}
+
+// MetricsSnapshot is a copy of the log volume counters
+type MetricsSnapshot struct {
+	// Entries are the entries written
+	Entries []EntryCount `json:"entries"`
+	// Dropped are the entries dropped by sampling
+	Dropped []EntryCount `json:"dropped"`
+	// Sampled are the entries kept by sampling
+	Sampled []EntryCount `json:"sampled"`
+	// SinkBytes are the bytes written per sink
+	SinkBytes map[string]uint64 `json:"sink_bytes"`
+	// SinkErrors are the write errors per sink
+	SinkErrors map[string]uint64 `json:"sink_errors"`
+}
+
+// entryKey identifies an entry counter
+type entryKey struct {
+	logger, level, prefix string
+}
+
+// counters is a set of labelled counters safe for concurrent use
+type counters[K comparable] struct {
+	m sync.Map
+}
+
+// add increments the counter of the key by n
+func (c *counters[K]) add(key K, n uint64) {
+	v, ok := c.m.Load(key)
+	if !ok {
+		v, _ = c.m.LoadOrStore(key, new(atomic.Uint64))
+	}
+	v.(*atomic.Uint64).Add(n)
+}
+
+// each calls fn for every counter
+func (c *counters[K]) each(fn func(key K, n uint64)) {
+	c.m.Range(func(k, v interface{}) bool {
+		fn(k.(K), v.(*atomic.Uint64).Load())
+		return true
+	})
+}
+
+// metrics holds the log volume counters of every logger created by this package
+var metrics struct {
+	entries    counters[entryKey]
+	dropped    counters[entryKey]
+	sampled    counters[entryKey]
+	sinkBytes  counters[string]
+	sinkErrors counters[string]
+}
+
+// ExpvarName is the name of the expvar variable publishing ReadMetrics
+const ExpvarName = "github.com/restoflife/log"
+
+func init() {
+	expvar.Publish(ExpvarName, expvar.Func(func() interface{} {
+		return ReadMetrics()
+	}))
+}
+
+// ReadMetrics returns a copy of the log volume counters
+func ReadMetrics() MetricsSnapshot {
+	s := MetricsSnapshot{
+		Entries:    readEntryCounts(&metrics.entries),
+		Dropped:    readEntryCounts(&metrics.dropped),
+		Sampled:    readEntryCounts(&metrics.sampled),
+		SinkBytes:  make(map[string]uint64),
+		SinkErrors: make(map[string]uint64),
+	}
+	metrics.sinkBytes.each(func(k string, n uint64) {
+		s.SinkBytes[k] = n
+	})
+	metrics.sinkErrors.each(func(k string, n uint64) {
+		s.SinkErrors[k] = n
+	})
+	return s
+}
+
+// readEntryCounts returns the entry counters sorted by their labels
+func readEntryCounts(c *counters[entryKey]) []EntryCount {
+	res := make([]EntryCount, 0)
+	c.each(func(k entryKey, n uint64) {
+		res = append(res, EntryCount{Logger: k.logger, Level: k.level, Prefix: k.prefix, Count: n})
+	})
+	sort.Slice(res, func(i, j int) bool {
+		a, b := res[i], res[j]
+		if a.Logger != b.Logger {
+			return a.Logger < b.Logger
+		}
+		if a.Level != b.Level {
+			return a.Level < b.Level
+		}
+		return a.Prefix < b.Prefix
+	})
+	return res
+}
+
+// metricPrefixes are the message prefixes counted apart, any other message
+// prefix would add series without bound
+var metricPrefixes = []string{GIN, SQL, GORM, XORM, ELASTIC}
+
+// metricPrefix returns the prefix label of the message, empty unless it is a prefix of this package
+func metricPrefix(msg string) string {
+	if p := logfile.MessagePrefix(msg); contains(metricPrefixes, p) {
+		return p
+	}
+	return ""
+}
+
+// countEntry is a zap hook counting the written entries
+func countEntry(ent zapcore.Entry) error {
+	metrics.entries.add(entryKey{ent.LoggerName, ent.Level.String(), metricPrefix(ent.Message)}, 1)
+	return nil
+}
+
+// countSampling is a zapcore.SamplerHook counting the sampling decisions
+func countSampling(ent zapcore.Entry, dec zapcore.SamplingDecision) {
+	key := entryKey{ent.LoggerName, ent.Level.String(), metricPrefix(ent.Message)}
+	if dec&zapcore.LogDropped > 0 {
+		metrics.dropped.add(key, 1)
+	}
+	if dec&zapcore.LogSampled > 0 {
+		metrics.sampled.add(key, 1)
+	}
+}
+
+// countingWriter counts the bytes and the write errors of a sink
+type countingWriter struct {
+	zapcore.WriteSyncer
+	sink string
+}
+
+// newCountingWriter wraps the sink so that its volume shows up in the metrics
+func newCountingWriter(sink string, w zapcore.WriteSyncer) zapcore.WriteSyncer {
+	return &countingWriter{WriteSyncer: w, sink: sink}
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.WriteSyncer.Write(p)
+	metrics.sinkBytes.add(w.sink, uint64(n))
+	if err != nil {
+		metrics.sinkErrors.add(w.sink, 1)
+	}
+	return n, err
+}
+
+func (w *countingWriter) Sync() error {
+	err := w.WriteSyncer.Sync()
+	if err != nil {
+		metrics.sinkErrors.add(w.sink, 1)
+	}
+	return err
+}
+
+// MetricsHandler returns an http.Handler writing the log volume counters in the Prometheus text exposition format
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		writeMetrics(w, ReadMetrics())
+	})
+}
+
+// writeMetrics writes the snapshot in the Prometheus text exposition format
+func writeMetrics(w io.Writer, s MetricsSnapshot) {
+	entries := func(name, help string, counts []EntryCount) {
+		_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+		for _, c := range counts {
+			_, _ = fmt.Fprintf(w, "%s{logger=%s,level=%s,prefix=%s} %d\n", name, quoteLabel(c.Logger), quoteLabel(c.Level), quoteLabel(c.Prefix), c.Count)
+		}
+	}
+	sinks := func(name, help string, counts map[string]uint64) {
+		_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+		keys := make([]string, 0, len(counts))
+		for k := range counts {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			_, _ = fmt.Fprintf(w, "%s{sink=%s} %d\n", name, quoteLabel(k), counts[k])
+		}
+	}
+	entries("log_entries_total", "Log entries written by logger, level and prefix.", s.Entries)
+	entries("log_entries_dropped_total", "Log entries dropped by sampling.", s.Dropped)
+	entries("log_entries_sampled_total", "Log entries kept by sampling.", s.Sampled)
+	sinks("log_sink_bytes_total", "Bytes written per sink.", s.SinkBytes)
+	sinks("log_sink_write_errors_total", "Write errors per sink.", s.SinkErrors)
+}
+
+// quoteLabel quotes a Prometheus label value
+func quoteLabel(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
@@ -0,0 +1,67 @@
+package log
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	c := &Config{
+		Level:    "info",
+		Filename: filepath.Join(t.TempDir(), "metrics.log"),
+		Console:  "fatal",
+		Sampling: &SamplingConfig{Initial: 1, Thereafter: 100},
+	}
+	lg := c.NewLogger().Named("metrics")
+	for i := 0; i < 3; i++ {
+		lg.Info(GIN)
+	}
+	lg.Debug(GIN)
+	lg.Info("[user 42] signed in")
+	lg.Info("[Recovery]")
+
+	s := ReadMetrics()
+	find := func(counts []EntryCount) uint64 {
+		for _, e := range counts {
+			if e.Logger == "metrics" && e.Level == "info" && e.Prefix == GIN {
+				return e.Count
+			}
+		}
+		return 0
+	}
+	if n := find(s.Entries); n != 1 {
+		t.Errorf("entries = %d, want 1", n)
+	}
+	if n := find(s.Sampled); n != 1 {
+		t.Errorf("sampled = %d, want 1", n)
+	}
+	if n := find(s.Dropped); n != 2 {
+		t.Errorf("dropped = %d, want 2", n)
+	}
+	// Other prefixes share the empty label
+	for _, e := range s.Entries {
+		if e.Logger == "metrics" && e.Prefix != GIN && e.Prefix != "" {
+			t.Errorf("unexpected prefix label %q", e.Prefix)
+		}
+	}
+	if s.SinkBytes["file:"+c.Filename] == 0 {
+		t.Errorf("no bytes counted for the file sink: %v", s.SinkBytes)
+	}
+
+	rec := httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE log_entries_total counter",
+		`log_entries_total{logger="metrics",level="info",prefix="[GIN]"} 1`,
+		`log_entries_dropped_total{logger="metrics",level="info",prefix="[GIN]"} 2`,
+		`log_sink_bytes_total{sink="file:` + c.Filename + `"}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 21:00
+ * @FilePath: log//redirect.go
+ */
+
+package log
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	stdlog "log"
+	"strings"
+)
+
+// levelTags are the leading tags giving the level of a redirected line, checked in order
+var levelTags = []struct {
+	tag   string
+	level zapcore.Level
+}{
+	{"[WARNING]", zapcore.WarnLevel},
+	{"[WARN]", zapcore.WarnLevel},
+	{"WARNING:", zapcore.WarnLevel},
+	{"WARN:", zapcore.WarnLevel},
+	{"[ERROR]", zapcore.ErrorLevel},
+	{"ERROR:", zapcore.ErrorLevel},
+	{"[DEBUG]", zapcore.DebugLevel},
+	{"DEBUG:", zapcore.DebugLevel},
+	{"[INFO]", zapcore.InfoLevel},
+	{"INFO:", zapcore.InfoLevel},
+}
+
+// RedirectStd sends the output of the standard logger, gin.DefaultWriter,
+// gin.DefaultErrorWriter and gin.DebugPrintRouteFunc to the logger, nil uses the
+// default logger, the output is discarded when New was not called. Lines are logged at the level of their leading tag such as
+// [WARNING], gin debug lines at debug level and gin.DefaultErrorWriter at error
+// level. The returned function restores the previous writers.
+func RedirectStd(l *zap.Logger) (restore func()) {
+	if l == nil {
+		l = logger
+	}
+	if l == nil {
+		l = zap.NewNop()
+	}
+	// The callers of the writers are inside the std and gin packages
+	l = l.WithOptions(zap.WithCaller(false))
+	std, g := l.Named("stdlog"), l.Named("gin")
+
+	flags, prefix, output := stdlog.Flags(), stdlog.Prefix(), stdlog.Writer()
+	out, errOut, route := gin.DefaultWriter, gin.DefaultErrorWriter, gin.DebugPrintRouteFunc
+
+	stdlog.SetFlags(0)
+	stdlog.SetPrefix("")
+	stdlog.SetOutput(&lineWriter{log: std, level: zapcore.InfoLevel})
+	gin.DefaultWriter = &lineWriter{log: g, level: zapcore.InfoLevel}
+	gin.DefaultErrorWriter = &lineWriter{log: g, level: zapcore.ErrorLevel}
+	gin.DebugPrintRouteFunc = func(httpMethod, absolutePath, handlerName string, nuHandlers int) {
+		g.Debug("route",
+			zap.String("Method", httpMethod),
+			zap.String("Path", absolutePath),
+			zap.String("Handler", handlerName),
+			zap.Int("Handlers", nuHandlers),
+		)
+	}
+	return func() {
+		stdlog.SetFlags(flags)
+		stdlog.SetPrefix(prefix)
+		stdlog.SetOutput(output)
+		gin.DefaultWriter, gin.DefaultErrorWriter, gin.DebugPrintRouteFunc = out, errOut, route
+	}
+}
+
+// lineWriter logs every write as an entry, the std logger and gin write one entry per call
+type lineWriter struct {
+	log *zap.Logger
+	// level is used for the lines without level tag
+	level zapcore.Level
+}
+
+var _ io.Writer = (*lineWriter)(nil)
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSpace(string(p))
+	if msg == "" {
+		return len(p), nil
+	}
+	level := w.level
+	// Debug output of gin, its warnings are tagged after the debug tag
+	if rest, ok := strings.CutPrefix(msg, "[GIN-debug]"); ok {
+		msg, level = strings.TrimSpace(rest), zapcore.DebugLevel
+	}
+	for _, t := range levelTags {
+		if rest, ok := strings.CutPrefix(msg, t.tag); ok {
+			msg, level = strings.TrimSpace(rest), t.level
+			break
+		}
+	}
+	if ce := w.log.Check(level, msg); ce != nil {
+		ce.Write()
+	}
+	return len(p), nil
+}
@@ -0,0 +1,55 @@
+package log
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	stdlog "log"
+	"os"
+	"testing"
+)
+
+func TestRedirectStd(t *testing.T) {
+	r := NewRingBuffer(10)
+	restore := RedirectStd(zap.New(r.Core(zapcore.DebugLevel)))
+	stdlog.Printf("started on %d", 8080)
+	stdlog.Print("[WARNING] disk almost full")
+	_, _ = fmt.Fprintf(gin.DefaultWriter, "[GIN-debug] [WARNING] Running in \"debug\" mode\n")
+	_, _ = fmt.Fprintf(gin.DefaultWriter, "[GIN-debug] Listening on :8080\n")
+	_, _ = fmt.Fprintf(gin.DefaultErrorWriter, "[Recovery] panic recovered:\nboom\n")
+	gin.DebugPrintRouteFunc("GET", "/ping", "main.ping", 3)
+	restore()
+	if stdlog.Writer() != os.Stderr || gin.DefaultWriter != os.Stdout || gin.DebugPrintRouteFunc != nil {
+		t.Fatal("writers not restored")
+	}
+
+	want := []string{
+		"stdlog INFO started on 8080",
+		"stdlog WARN disk almost full",
+		"gin WARN Running in \"debug\" mode",
+		"gin DEBUG Listening on :8080",
+		"gin ERROR [Recovery] panic recovered:\nboom",
+		"gin DEBUG route",
+	}
+	entries := r.Entries(RingFilter{})
+	if len(entries) != len(want) {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	for i, e := range entries {
+		if got := e.Logger + " " + e.Level + " " + e.Message; got != want[i] {
+			t.Errorf("entry %d: got %q, want %q", i, got, want[i])
+		}
+	}
+	if f := entries[5].Fields; f["Path"] != "/ping" || f["Handlers"] != int64(3) {
+		t.Errorf("unexpected route fields %v", f)
+	}
+}
+
+func TestRedirectStdNil(t *testing.T) {
+	defer func(l *zap.Logger) { logger = l }(logger)
+	logger = nil
+	restore := RedirectStd(nil)
+	stdlog.Print("discarded")
+	restore()
+}
@@ -0,0 +1,166 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 22:30
+ * @FilePath: log//requestid.go
+ */
+
+package log
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// RequestIDHeader is the default header of the request ID
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDField is the default field name of the request ID
+	RequestIDField = "request_id"
+
+	// ginLoggerKey, ginFieldsKey and ginRequestIDKey are the gin.Context keys of the request logger, fields and ID
+	ginLoggerKey    = "github.com/restoflife/log/logger"
+	ginFieldsKey    = "github.com/restoflife/log/fields"
+	ginRequestIDKey = "github.com/restoflife/log/request_id"
+	// maxRequestIDLen bounds the IDs accepted from the clients
+	maxRequestIDLen = 128
+)
+
+// ctxLoggerKey and ctxRequestIDKey are the context keys of the request logger and ID
+type (
+	ctxLoggerKey    struct{}
+	ctxRequestIDKey struct{}
+)
+
+// RequestIDConfig defines the config for RequestID middleware.
+type RequestIDConfig struct {
+	// Header is read from the request and written to the response, default X-Request-ID
+	Header string
+	// Headers are read in order when Header is missing, e.g. X-Correlation-ID
+	Headers []string
+	// Generator creates the ID of the requests without one, default 16 random bytes in hex
+	Generator func() string
+	// Field is the field name of the ID, default request_id
+	Field string
+}
+
+// RequestID instances a middleware reading or creating the X-Request-ID of each
+// request, see RequestIDWithConfig.
+func RequestID(logger *zap.Logger) gin.HandlerFunc {
+	return RequestIDWithConfig(logger, RequestIDConfig{})
+}
+
+// RequestIDWithConfig instances a middleware reading the request ID from the
+// headers or creating one, and echoing it in the response. A child logger
+// carrying the ID is stored in the gin.Context and the request context, see
+// FromGin and FromContext. The access log of WithConfig and the Recovery
+// output carry the ID as well.
+func RequestIDWithConfig(logger *zap.Logger, conf RequestIDConfig) gin.HandlerFunc {
+	if conf.Header == "" {
+		conf.Header = RequestIDHeader
+	}
+	if conf.Generator == nil {
+		conf.Generator = newRequestID
+	}
+	if conf.Field == "" {
+		conf.Field = RequestIDField
+	}
+	headers := append([]string{conf.Header}, conf.Headers...)
+	return func(c *gin.Context) {
+		var id string
+		for _, h := range headers {
+			if id = c.GetHeader(h); validRequestID(id) {
+				break
+			}
+			id = ""
+		}
+		if id == "" {
+			id = conf.Generator()
+		}
+		c.Header(conf.Header, id)
+
+		c.Set(ginRequestIDKey, id)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), ctxRequestIDKey{}, id))
+		addRequestFields(c, logger, zap.String(conf.Field, id))
+		c.Next()
+	}
+}
+
+// FromGin returns the request logger stored by RequestID, or the default logger
+func FromGin(c *gin.Context) *zap.Logger {
+	v, _ := c.Get(ginLoggerKey)
+	if lg, _ := v.(*zap.Logger); lg != nil {
+		return lg
+	}
+	return Logger()
+}
+
+// FromContext returns the request logger stored by RequestID, or the default logger
+func FromContext(ctx context.Context) *zap.Logger {
+	if lg, ok := ctx.Value(ctxLoggerKey{}).(*zap.Logger); ok && lg != nil {
+		return lg
+	}
+	return Logger()
+}
+
+// RequestIDFromGin returns the request ID set by RequestID
+func RequestIDFromGin(c *gin.Context) string {
+	return c.GetString(ginRequestIDKey)
+}
+
+// RequestIDFromContext returns the request ID set by RequestID
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(ctxRequestIDKey{}).(string)
+	return id
+}
+
+// addRequestFields adds fields identifying the request to the access log, the
+// Recovery output, the request logger and the request context
+func addRequestFields(c *gin.Context, logger *zap.Logger, fields ...zap.Field) {
+	prev := requestFields(c)
+	c.Set(ginFieldsKey, append(prev[:len(prev):len(prev)], fields...))
+
+	// The request logger of a previous middleware already has its fields
+	lg := logger
+	if v, ok := c.Get(ginLoggerKey); ok {
+		lg, _ = v.(*zap.Logger)
+	} else if lg == nil {
+		lg = Logger()
+	}
+	if lg != nil {
+		lg = lg.With(fields...)
+	}
+	c.Set(ginLoggerKey, lg)
+	ctx := WithFields(c.Request.Context(), fields...)
+	c.Request = c.Request.WithContext(context.WithValue(ctx, ctxLoggerKey{}, lg))
+}
+
+// requestFields returns the fields identifying the request in the access log and the Recovery output
+func requestFields(c *gin.Context) []zap.Field {
+	v, _ := c.Get(ginFieldsKey)
+	fields, _ := v.([]zap.Field)
+	return fields
+}
+
+// newRequestID returns 16 random bytes in hex
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// validRequestID reports whether the ID given by a client can be logged as is
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
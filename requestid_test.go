@@ -0,0 +1,48 @@
+package log_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/restoflife/log"
+	"github.com/restoflife/log/logtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	lg, rec := logtest.New(zapcore.DebugLevel)
+	engine := logtest.NewGin(log.RequestIDWithConfig(lg, log.RequestIDConfig{
+		Headers:   []string{"X-Correlation-ID"},
+		Generator: func() string { return "generated" },
+	}), log.Recovery(lg), log.GinLogger(lg))
+	engine.GET("/ok", func(c *gin.Context) {
+		log.FromGin(c).Info("handler")
+		log.FromContext(c.Request.Context()).Info("service")
+		lg.Info("context", log.ContextFields(c.Request.Context())...)
+		c.String(http.StatusOK, log.RequestIDFromGin(c))
+	})
+	engine.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ok", nil)
+	req.Header.Set("X-Correlation-ID", "abc-123")
+	if res := logtest.ServeGin(engine, req); res.Header().Get(log.RequestIDHeader) != "abc-123" || res.Body.String() != "abc-123" {
+		t.Fatalf("unexpected response %v %q", res.Header(), res.Body)
+	}
+	id := zap.String(log.RequestIDField, "abc-123")
+	rec.AssertLogged(t, zapcore.InfoLevel, "handler", id)
+	rec.AssertLogged(t, zapcore.InfoLevel, "service", id)
+	rec.AssertLogged(t, zapcore.InfoLevel, "context", id)
+	rec.AssertLogged(t, zapcore.InfoLevel, log.GIN, zap.String("Path", "/ok"), id)
+
+	// Invalid IDs are replaced
+	req = httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set(log.RequestIDHeader, "bad id\n")
+	if res := logtest.ServeGin(engine, req); res.Header().Get(log.RequestIDHeader) != "generated" {
+		t.Fatalf("unexpected response %v", res.Header())
+	}
+	rec.AssertLogged(t, zapcore.ErrorLevel, "[Recovery]", zap.String(log.RequestIDField, "generated"))
+}
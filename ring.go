@@ -0,0 +1,242 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 10:12
+ * @FilePath: log//ring.go
+ */
+
+package log
+
+import (
+	"fmt"
+	"github.com/restoflife/log/logfile"
+	"go.uber.org/zap/zapcore"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RingEntry is a log entry kept in memory by a RingBuffer
+type RingEntry struct {
+	// Seq is the sequence number of the entry, starting at 1
+	Seq uint64 `json:"seq"`
+	// Time is the time the entry was logged
+	Time time.Time `json:"time"`
+	// Level is the level of the entry
+	Level string `json:"level"`
+	// Logger is the name of the logger, see zap.Logger.Named
+	Logger string `json:"logger,omitempty"`
+	// Caller is the file and line of the caller, if known
+	Caller string `json:"caller,omitempty"`
+	// Message is the log message
+	Message string `json:"message"`
+	// Prefix is the message prefix such as [GIN], [SQL], [GORM] or [ELASTIC]
+	Prefix string `json:"prefix,omitempty"`
+	// Fields are the context fields of the entry
+	Fields map[string]interface{} `json:"fields,omitempty"`
+}
+
+// RingFilter selects entries from a RingBuffer, empty values match everything
+type RingFilter struct {
+	// Level selects the levels of the entries, e.g. zapcore.WarnLevel for warn and above
+	Level zapcore.LevelEnabler
+	// Logger matches the logger name or one of its parents
+	Logger string
+	// Prefix matches the message prefix, e.g. "gin" or "[GIN]"
+	Prefix string
+	// Contains matches a substring of the message or of the field values
+	Contains string
+	// Limit is the maximum number of entries returned, the newest ones are kept
+	Limit int
+}
+
+// Match reports whether the entry passes the filter
+func (f RingFilter) Match(e RingEntry) bool {
+	if f.Level != nil {
+		var lvl zapcore.Level
+		if err := lvl.UnmarshalText([]byte(e.Level)); err == nil && !f.Level.Enabled(lvl) {
+			return false
+		}
+	}
+	if f.Logger != "" && e.Logger != f.Logger && !strings.HasPrefix(e.Logger, f.Logger+".") {
+		return false
+	}
+	if f.Prefix != "" && !logfile.MatchPrefix(e.Prefix, f.Prefix) {
+		return false
+	}
+	if f.Contains != "" && !strings.Contains(e.Message, f.Contains) && !containsField(e.Fields, f.Contains) {
+		return false
+	}
+	return true
+}
+
+// RingBuffer keeps the last N log entries in memory
+type RingBuffer struct {
+	mu      sync.RWMutex
+	entries []RingEntry
+	// next is the index the next entry is written to
+	next int
+	// full is set once the buffer has wrapped around
+	full bool
+	seq  uint64
+	// subs are the live tail subscribers
+	subs map[chan RingEntry]struct{}
+}
+
+// NewRingBuffer Create a new RingBuffer keeping the last size entries
+func NewRingBuffer(size int) *RingBuffer {
+	if size <= 0 {
+		size = 1
+	}
+	return &RingBuffer{
+		entries: make([]RingEntry, size),
+		subs:    make(map[chan RingEntry]struct{}),
+	}
+}
+
+// Add appends an entry, overwriting the oldest one when the buffer is full
+func (r *RingBuffer) Add(e RingEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	e.Seq = r.seq
+	r.entries[r.next] = e
+	r.next++
+	if r.next == len(r.entries) {
+		r.next = 0
+		r.full = true
+	}
+	// Never block the logger on a slow subscriber
+	for ch := range r.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Entries returns the entries matching the filter, oldest first
+func (r *RingBuffer) Entries(f RingFilter) []RingEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var all []RingEntry
+	if r.full {
+		all = append(all, r.entries[r.next:]...)
+	}
+	all = append(all, r.entries[:r.next]...)
+	res := make([]RingEntry, 0, len(all))
+	for _, e := range all {
+		if f.Match(e) {
+			res = append(res, e)
+		}
+	}
+	if f.Limit > 0 && len(res) > f.Limit {
+		res = res[len(res)-f.Limit:]
+	}
+	return res
+}
+
+// Len returns the number of entries currently kept
+func (r *RingBuffer) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.full {
+		return len(r.entries)
+	}
+	return r.next
+}
+
+// Subscribe returns a channel receiving every new entry and a function to cancel the subscription
+func (r *RingBuffer) Subscribe(buffer int) (<-chan RingEntry, func()) {
+	ch := make(chan RingEntry, buffer)
+	r.mu.Lock()
+	r.subs[ch] = struct{}{}
+	r.mu.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			r.mu.Lock()
+			delete(r.subs, ch)
+			r.mu.Unlock()
+		})
+	}
+}
+
+// Core returns a zapcore.Core that records the enabled entries into the buffer
+func (r *RingBuffer) Core(enab zapcore.LevelEnabler) zapcore.Core {
+	return &ringCore{LevelEnabler: enab, ring: r}
+}
+
+// ringCore is a zapcore.Core writing into a RingBuffer
+type ringCore struct {
+	zapcore.LevelEnabler
+	ring   *RingBuffer
+	fields []zapcore.Field
+}
+
+func (c *ringCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = append(clone.fields[:len(clone.fields):len(clone.fields)], fields...)
+	return &clone
+}
+
+func (c *ringCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *ringCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	e := RingEntry{
+		Time:    ent.Time,
+		Level:   ent.Level.CapitalString(),
+		Logger:  ent.LoggerName,
+		Message: ent.Message,
+		Prefix:  logfile.MessagePrefix(ent.Message),
+	}
+	if ent.Caller.Defined {
+		e.Caller = ent.Caller.TrimmedPath()
+	}
+	if len(enc.Fields) > 0 {
+		e.Fields = enc.Fields
+	}
+	c.ring.Add(e)
+	return nil
+}
+
+func (c *ringCore) Sync() error {
+	return nil
+}
+
+// containsField reports whether any field value contains the substring
+func containsField(fields map[string]interface{}, sub string) bool {
+	for k, v := range fields {
+		if strings.Contains(k, sub) {
+			return true
+		}
+		if s, ok := v.(string); ok {
+			if strings.Contains(s, sub) {
+				return true
+			}
+			continue
+		}
+		if m, ok := v.(map[string]interface{}); ok {
+			if containsField(m, sub) {
+				return true
+			}
+			continue
+		}
+		if strings.Contains(fmt.Sprint(v), sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 10:40
+ * @FilePath: log//ring_http.go
+ */
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ringTemplateText renders the entries of a RingBuffer as an HTML page
+const ringTemplateText = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Recent log entries</title>
+<style>
+body{font-family:monospace;font-size:13px;margin:16px}
+table{border-collapse:collapse;width:100%}
+td,th{border-bottom:1px solid #ddd;padding:4px 6px;text-align:left;vertical-align:top}
+.DEBUG{color:#888}.WARN{color:#b8860b}.ERROR,.DPANIC,.PANIC,.FATAL{color:#c00}
+</style>
+</head>
+<body>
+<form method="get">
+level <input name="level" value="{{.Query.level}}" size="6">
+logger <input name="logger" value="{{.Query.logger}}" size="10">
+prefix <input name="prefix" value="{{.Query.prefix}}" size="8">
+contains <input name="q" value="{{.Query.q}}" size="20">
+limit <input name="limit" value="{{.Query.limit}}" size="5">
+<input type="hidden" name="format" value="html">
+<button type="submit">filter</button>
+</form>
+<p>{{len .Entries}} entries</p>
+<table>
+<tr><th>#</th><th>time</th><th>level</th><th>logger</th><th>message</th><th>fields</th></tr>
+{{range .Entries}}<tr class="{{.Level}}"><td>{{.Seq}}</td><td>{{.Time.Format "2006-01-02T15:04:05.000Z07:00"}}</td><td>{{.Level}}</td><td>{{.Logger}}</td><td>{{.Message}}</td><td>{{json .Fields}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`
+
+// RingHandler returns an http.Handler serving the entries of the RingBuffer.
+// Query parameters:
+//
+//	level   minimum level (debug, info, warn, error...)
+//	logger  logger name given to zap.Logger.Named
+//	prefix  message prefix such as gin, sql, gorm or elastic
+//	q       substring of the message or the field values
+//	limit   maximum number of entries
+//	format  json (default) or html
+//	stream  when set, tail the new entries as server-sent events
+func RingHandler(r *RingBuffer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		filter, err := parseRingFilter(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		query := req.URL.Query()
+		// Live tail with server-sent events
+		if query.Get("stream") != "" || strings.Contains(req.Header.Get("Accept"), "text/event-stream") {
+			serveRingStream(w, req, r, filter)
+			return
+		}
+		entries := r.Entries(filter)
+		if query.Get("format") == "html" || (query.Get("format") == "" && strings.Contains(req.Header.Get("Accept"), "text/html")) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			flat := make(map[string]string, len(query))
+			for k := range query {
+				flat[k] = query.Get(k)
+			}
+			_ = ringHTML.Execute(w, map[string]interface{}{"Entries": entries, "Query": flat})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_ = json.NewEncoder(w).Encode(entries)
+	})
+}
+
+// GinRingHandler returns a gin.HandlerFunc serving the entries of the RingBuffer, see RingHandler
+func GinRingHandler(r *RingBuffer) gin.HandlerFunc {
+	return gin.WrapH(RingHandler(r))
+}
+
+// ringHTML is the parsed ringTemplateText
+var ringHTML = template.Must(template.New("ring").Funcs(template.FuncMap{
+	"json": func(v map[string]interface{}) string {
+		if len(v) == 0 {
+			return ""
+		}
+		b, _ := json.Marshal(v)
+		return string(b)
+	},
+}).Parse(ringTemplateText))
+
+// parseRingFilter builds a RingFilter from the query string of the request
+func parseRingFilter(req *http.Request) (RingFilter, error) {
+	query := req.URL.Query()
+	f := RingFilter{
+		Logger:   query.Get("logger"),
+		Prefix:   query.Get("prefix"),
+		Contains: query.Get("q"),
+	}
+	if s := query.Get("level"); s != "" {
+		var lvl zapcore.Level
+		if err := lvl.UnmarshalText([]byte(s)); err != nil {
+			return f, err
+		}
+		f.Level = lvl
+	}
+	if s := query.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return f, fmt.Errorf("invalid limit %q", s)
+		}
+		f.Limit = n
+	}
+	return f, nil
+}
+
+// serveRingStream writes the new entries matching the filter as server-sent events until the client goes away
+func serveRingStream(w http.ResponseWriter, req *http.Request, r *RingBuffer, filter RingFilter) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	ch, cancel := r.Subscribe(256)
+	defer cancel()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case e := <-ch:
+			if !filter.Match(e) {
+				continue
+			}
+			b, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			if _, err = fmt.Fprintf(w, "id: %d\ndata: %s\n\n", e.Seq, b); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package log
+
+import (
+	"encoding/json"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRingBuffer(t *testing.T) {
+	r := NewRingBuffer(3)
+	lg := zap.New(r.Core(zapcore.DebugLevel)).Named("api")
+	lg.Info(GIN, zap.String("Path", "/a"))
+	lg.Debug("debug")
+	lg.With(zap.String("tenant_id", "t1")).Warn(SQL, zap.String("SQL", "select 1"))
+	lg.Error(GIN, zap.String("Path", "/b"))
+
+	all := r.Entries(RingFilter{})
+	if len(all) != 3 || all[0].Message != "debug" || all[2].Seq != 4 {
+		t.Fatalf("unexpected entries %+v", all)
+	}
+	if got := r.Entries(RingFilter{Prefix: "gin"}); len(got) != 1 || got[0].Fields["Path"] != "/b" {
+		t.Fatalf("prefix filter %+v", got)
+	}
+	if got := r.Entries(RingFilter{Level: zapcore.WarnLevel}); len(got) != 2 {
+		t.Fatalf("level filter %+v", got)
+	}
+	if got := r.Entries(RingFilter{Contains: "t1"}); len(got) != 1 || got[0].Prefix != SQL {
+		t.Fatalf("contains filter %+v", got)
+	}
+	if got := r.Entries(RingFilter{Logger: "other"}); len(got) != 0 {
+		t.Fatalf("logger filter %+v", got)
+	}
+}
+
+func TestRingHandler(t *testing.T) {
+	r := NewRingBuffer(10)
+	lg := zap.New(r.Core(zapcore.DebugLevel))
+	lg.Info(GIN, zap.Int("Code", 200))
+	lg.Error(ELASTIC, zap.String("Status", "500"))
+
+	rec := httptest.NewRecorder()
+	RingHandler(r).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?level=error", nil))
+	var entries []RingEntry
+	if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Prefix != ELASTIC {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+
+	rec = httptest.NewRecorder()
+	RingHandler(r).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?format=html&prefix=gin", nil))
+	if body := rec.Body.String(); !strings.Contains(body, "[GIN]") || strings.Contains(body, "[ELASTIC]") {
+		t.Fatalf("unexpected html %s", body)
+	}
+
+	rec = httptest.NewRecorder()
+	RingHandler(r).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?level=nope", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+}
+
+func TestRingLevel(t *testing.T) {
+	for _, c := range []struct {
+		level, ring string
+		want        int
+	}{
+		{level: "warn", ring: "debug", want: 3},
+		{level: "warn", ring: "bogus", want: 1},
+		{level: "bogus", ring: "bogus", want: 2},
+	} {
+		conf := &Config{Level: c.level, Console: "fatal", Filename: filepath.Join(t.TempDir(), "app.log"), RingSize: 10, RingLevel: c.ring}
+		lg := conf.NewLogger()
+		lg.Debug("debug")
+		lg.Info("info")
+		lg.Warn("warn")
+		if n := conf.Ring().Len(); n != c.want {
+			t.Fatalf("level %s, ring level %s: %d entries", c.level, c.ring, n)
+		}
+	}
+}
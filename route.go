@@ -0,0 +1,242 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 20:05
+ * @FilePath: log//route.go
+ */
+
+package log
+
+import (
+	"errors"
+	"fmt"
+	"github.com/restoflife/log/logfile"
+	"go.uber.org/zap/zapcore"
+	"strings"
+)
+
+// RouteConfig sends the matching entries to a file of their own. The conditions
+// that are set must all match, any value of a list matches.
+type RouteConfig struct {
+	// 消息前缀, 如 GIN、[SQL]、GORM
+	Prefix []string `json:"prefix"`
+	// 日志名称, 子日志如 api.user 也匹配 api
+	Logger []string `json:"logger"`
+	// 字段值, 如 {"tenant_id": "42"}, "*" 表示字段存在即可
+	Fields map[string]string `json:"fields"`
+	// 设置日志文件名
+	Filename string `json:"file"`
+	// 日志记录级别, 默认使用 Config.Levels
+	Level string `json:"level"`
+	// 设置每个日志文件的最大大小, 默认与 Config 相同
+	MaxSize int `json:"max_size"`
+	// 设置每个日志文件的最大备份数, 默认与 Config 相同
+	MaxBackups int `json:"max_backups"`
+	// 设置每个日志文件的最大保存时间, 默认与 Config 相同
+	MaxAge int `json:"max_age"`
+	// 匹配后继续写入主日志文件
+	Continue bool `json:"continue"`
+
+	// file is the writer of Filename, shared by every logger of the Config
+	file zapcore.WriteSyncer
+}
+
+// match reports whether the entry matches the route, fields are only encoded when needed
+func (r *RouteConfig) match(ent zapcore.Entry, fields func() map[string]interface{}) bool {
+	if len(r.Prefix) > 0 {
+		prefix := logfile.MessagePrefix(ent.Message)
+		ok := false
+		for _, p := range r.Prefix {
+			if prefix != "" && logfile.MatchPrefix(prefix, p) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	if len(r.Logger) > 0 {
+		ok := false
+		for _, name := range r.Logger {
+			if ent.LoggerName == name || strings.HasPrefix(ent.LoggerName, name+".") {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	if len(r.Fields) > 0 {
+		values := fields()
+		for k, want := range r.Fields {
+			v, ok := values[k]
+			if !ok || (want != "*" && fmt.Sprint(v) != want) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// newFileWriter Create the writer of the route, rotation defaults to the Config
+func (r *RouteConfig) newFileWriter(l *Config) (zapcore.WriteSyncer, error) {
+	if r.file == nil {
+		c := &Config{
+			Filename:   r.Filename,
+			MaxSize:    r.MaxSize,
+			MaxBackups: r.MaxBackups,
+			MaxAge:     r.MaxAge,
+			Encryption: l.Encryption,
+			Chain:      l.Chain,
+		}
+		if c.MaxSize == 0 {
+			c.MaxSize = l.MaxSize
+		}
+		if c.MaxBackups == 0 {
+			c.MaxBackups = l.MaxBackups
+		}
+		if c.MaxAge == 0 {
+			c.MaxAge = l.MaxAge
+		}
+		w, err := c.newFileWriter()
+		if err != nil {
+			return nil, err
+		}
+		r.file = newCountingWriter("file:"+r.Filename, zapcore.AddSync(w))
+	}
+	return r.file, nil
+}
+
+// route is a RouteConfig with its core
+type route struct {
+	*RouteConfig
+	core zapcore.Core
+}
+
+// routeCore writes each entry to the core of the first matching route, or to the main core
+type routeCore struct {
+	main   zapcore.Core
+	routes []route
+	// fields are the fields added by With, needed to match the routes
+	fields []zapcore.Field
+	// needFields is set when a route matches field values
+	needFields bool
+}
+
+// newRouteCore Create the core writing the entries to the main file and the route files
+func (l *Config) newRouteCore(encoder zapcore.Encoder, main zapcore.Core) (zapcore.Core, error) {
+	c := &routeCore{main: main}
+	for _, r := range l.Routes {
+		if r == nil || r.Filename == "" {
+			continue
+		}
+		var lvl zapcore.Level
+		if r.Level != "" {
+			var err error
+			if lvl, err = zapcore.ParseLevel(r.Level); err != nil {
+				return nil, fmt.Errorf("log: level of the route to %s: %w", r.Filename, err)
+			}
+		}
+		w, err := r.newFileWriter(l)
+		if err != nil {
+			return nil, err
+		}
+		// Without level of its own the route follows the level spec of the Config
+		var core zapcore.Core
+		if r.Level != "" {
+			core = l.newFileCore(encoder.Clone(), w, lvl)
+		} else {
+			core = &levelCore{Core: l.newFileCore(encoder.Clone(), w, l.levels), levels: l.levels}
+		}
+		c.routes = append(c.routes, route{RouteConfig: r, core: core})
+		c.needFields = c.needFields || len(r.Fields) > 0
+	}
+	return c, nil
+}
+
+func (c *routeCore) Enabled(lvl zapcore.Level) bool {
+	if c.main.Enabled(lvl) {
+		return true
+	}
+	for _, r := range c.routes {
+		if r.core.Enabled(lvl) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *routeCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &routeCore{
+		main:       c.main.With(fields),
+		routes:     make([]route, len(c.routes)),
+		needFields: c.needFields,
+	}
+	for i, r := range c.routes {
+		clone.routes[i] = route{RouteConfig: r.RouteConfig, core: r.core.With(fields)}
+	}
+	if c.needFields {
+		clone.fields = append(c.fields[:len(c.fields):len(c.fields)], fields...)
+	}
+	return clone
+}
+
+func (c *routeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	// The route depends on the fields, so it is chosen in Write
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *routeCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	var values map[string]interface{}
+	encode := func() map[string]interface{} {
+		if values == nil {
+			enc := zapcore.NewMapObjectEncoder()
+			for _, f := range c.fields {
+				f.AddTo(enc)
+			}
+			for _, f := range fields {
+				f.AddTo(enc)
+			}
+			values = enc.Fields
+		}
+		return values
+	}
+	for _, r := range c.routes {
+		if !r.match(ent, encode) {
+			continue
+		}
+		var err error
+		if enabled(r.core, ent) {
+			err = r.core.Write(ent, fields)
+		}
+		if r.Continue && enabled(c.main, ent) {
+			err = errors.Join(err, c.main.Write(ent, fields))
+		}
+		return err
+	}
+	if enabled(c.main, ent) {
+		return c.main.Write(ent, fields)
+	}
+	return nil
+}
+
+func (c *routeCore) Sync() error {
+	err := c.main.Sync()
+	for _, r := range c.routes {
+		err = errors.Join(err, r.core.Sync())
+	}
+	return err
+}
+
+// enabled reports whether the core writes the entry, the level of a levelCore depends on the entry
+func enabled(core zapcore.Core, ent zapcore.Entry) bool {
+	if c, ok := core.(*levelCore); ok {
+		return c.enabled(ent)
+	}
+	return core.Enabled(ent.Level)
+}
@@ -0,0 +1,73 @@
+package log
+
+import (
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRoutes(t *testing.T) {
+	dir := t.TempDir()
+	file := func(name string) string { return filepath.Join(dir, name) }
+	c := &Config{
+		Level:    "info",
+		Filename: file("app.log"),
+		Console:  "fatal",
+		Routes: []*RouteConfig{
+			{Fields: map[string]string{"tenant_id": "42"}, Filename: file("tenant.log"), Continue: true},
+			{Prefix: []string{"gin"}, Filename: file("gin.log")},
+			{Prefix: []string{SQL, GORM}, Filename: file("sql.log"), Level: "warn"},
+			{Logger: []string{"audit"}, Filename: file("audit.log")},
+		},
+	}
+	lg := c.NewLogger()
+	lg.Info(GIN, zap.String("Path", "/a"))
+	lg.Info(GORM, zap.String("SQL", "SELECT 1"))
+	lg.Warn(SQL, zap.String("SQL", "SELECT 2"))
+	lg.Info("started")
+	lg.Named("audit").Named("login").Info("login")
+	lg.With(zap.Int("tenant_id", 42)).Info(GIN, zap.String("Path", "/tenant"))
+	lg.Info("other tenant", zap.Int("tenant_id", 7))
+	_ = lg.Sync()
+
+	read := func(name string) string {
+		b, err := os.ReadFile(file(name))
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	want := map[string][]string{
+		"app.log":    {"started", "/tenant", "other tenant"},
+		"gin.log":    {"/a"},
+		"sql.log":    {"SELECT 2"},
+		"audit.log":  {"login"},
+		"tenant.log": {"/tenant"},
+	}
+	for name, subs := range want {
+		got := read(name)
+		if n := strings.Count(got, "\n"); n != len(subs) {
+			t.Errorf("%s: %d lines, want %d:\n%s", name, n, len(subs), got)
+		}
+		for _, s := range subs {
+			if !strings.Contains(got, s) {
+				t.Errorf("%s: missing %q:\n%s", name, s, got)
+			}
+		}
+	}
+}
+
+func TestRoutesInvalidLevel(t *testing.T) {
+	dir := t.TempDir()
+	c := &Config{
+		Level:    "info",
+		Filename: filepath.Join(dir, "app.log"),
+		Console:  "fatal",
+		Routes:   []*RouteConfig{{Prefix: []string{"gin"}, Filename: filepath.Join(dir, "gin.log"), Level: "warning"}},
+	}
+	if _, err := c.Build(); err == nil || !strings.Contains(err.Error(), "warning") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 20:40
+ * @FilePath: log//slog.go
+ */
+
+package log
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// SlogHandler is a slog.Handler writing to the cores of a zap logger, so that
+// slog records end up in the same files, rings and metrics. The fields of the
+// context passed to the slog calls are added, see ContextFields.
+type SlogHandler struct {
+	core zapcore.Core
+	name string
+	// fields are the attributes added within groups, they are written after
+	// the context fields which do not belong to the groups
+	fields []zapcore.Field
+	// groups are opened by WithGroup and not yet applied, empty groups are omitted
+	groups []string
+}
+
+// NewSlogHandler Create a slog.Handler writing to the logger, nil uses the
+// default logger, or discards the records when New has not been called
+func NewSlogHandler(l *zap.Logger) *SlogHandler {
+	if l == nil {
+		l = logger
+	}
+	if l == nil {
+		l = zap.NewNop()
+	}
+	return &SlogHandler{core: l.Core(), name: l.Name()}
+}
+
+// NewSlogHandler Create a slog.Handler writing to a new logger of the configuration
+func (l *Config) NewSlogHandler() *SlogHandler {
+	return NewSlogHandler(l.NewLogger())
+}
+
+// SetSlogDefault makes the logger the slog default, nil uses the default logger, see
+// NewSlogHandler, so that libraries using log/slog write to the same files
+func SetSlogDefault(l *zap.Logger) {
+	slog.SetDefault(slog.New(NewSlogHandler(l)))
+}
+
+// Enabled reports whether the logger writes records of the level
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.core.Enabled(zapLevel(level))
+}
+
+// Handle writes the record
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	ent := zapcore.Entry{
+		Level:      zapLevel(r.Level),
+		Time:       r.Time,
+		LoggerName: h.name,
+		Message:    r.Message,
+	}
+	if ent.Time.IsZero() {
+		ent.Time = time.Now()
+	}
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		ent.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+		ent.Caller.Function = frame.Function
+	}
+	ce := h.core.Check(ent, nil)
+	if ce == nil {
+		return nil
+	}
+	var attrs []zapcore.Field
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = appendAttr(attrs, a)
+		return true
+	})
+	// Context fields are not part of the groups of the handler, so they come first
+	ctxFields := ContextFields(ctx)
+	fields := make([]zapcore.Field, 0, len(ctxFields)+len(h.fields)+len(h.groups)+len(attrs))
+	fields = append(append(fields, ctxFields...), h.fields...)
+	if len(attrs) > 0 {
+		fields = append(append(fields, h.openGroups()...), attrs...)
+	}
+	ce.Write(fields...)
+	return nil
+}
+
+// WithAttrs returns a handler adding the attributes to every record
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var fields []zapcore.Field
+	for _, a := range attrs {
+		fields = appendAttr(fields, a)
+	}
+	if len(fields) == 0 {
+		return h
+	}
+	// Attributes outside groups are encoded once by the core
+	if len(h.fields) == 0 && len(h.groups) == 0 {
+		return &SlogHandler{core: h.core.With(fields), name: h.name}
+	}
+	clone := *h
+	clone.fields = append(append(h.fields[:len(h.fields):len(h.fields)], h.openGroups()...), fields...)
+	clone.groups = nil
+	return &clone
+}
+
+// WithGroup returns a handler nesting the attributes of the records under the group
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &clone
+}
+
+// openGroups returns the fields nesting the following fields under the pending groups
+func (h *SlogHandler) openGroups() []zapcore.Field {
+	fields := make([]zapcore.Field, 0, len(h.groups))
+	for _, g := range h.groups {
+		fields = append(fields, zap.Namespace(g))
+	}
+	return fields
+}
+
+// zapLevel maps a slog level to the zap level at or below it
+func zapLevel(level slog.Level) zapcore.Level {
+	switch {
+	case level >= slog.LevelError:
+		return zapcore.ErrorLevel
+	case level >= slog.LevelWarn:
+		return zapcore.WarnLevel
+	case level >= slog.LevelInfo:
+		return zapcore.InfoLevel
+	default:
+		return zapcore.DebugLevel
+	}
+}
+
+// appendAttr appends the field of the attribute, empty attributes and groups are omitted
+func appendAttr(fields []zapcore.Field, a slog.Attr) []zapcore.Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	switch v := a.Value; v.Kind() {
+	case slog.KindGroup:
+		attrs := v.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		// A group without key is inlined
+		if a.Key == "" {
+			for _, ga := range attrs {
+				fields = appendAttr(fields, ga)
+			}
+			return fields
+		}
+		return append(fields, zap.Object(a.Key, groupMarshaler(attrs)))
+	case slog.KindString:
+		return append(fields, zap.String(a.Key, v.String()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(a.Key, v.Int64()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(a.Key, v.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(a.Key, v.Float64()))
+	case slog.KindBool:
+		return append(fields, zap.Bool(a.Key, v.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(a.Key, v.Duration()))
+	case slog.KindTime:
+		return append(fields, zap.Time(a.Key, v.Time()))
+	default:
+		if err, ok := v.Any().(error); ok {
+			return append(fields, zap.NamedError(a.Key, err))
+		}
+		return append(fields, zap.Any(a.Key, v.Any()))
+	}
+}
+
+// groupMarshaler encodes the attributes of a group as an object
+type groupMarshaler []slog.Attr
+
+func (g groupMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	var fields []zapcore.Field
+	for _, a := range g {
+		fields = appendAttr(fields, a)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	r := NewRingBuffer(10)
+	lg := slog.New(NewSlogHandler(zap.New(r.Core(zapcore.InfoLevel)).Named("api")))
+
+	ctx := WithFields(context.Background(), zap.String("request_id", "r1"))
+	lg.DebugContext(ctx, "hidden")
+	lg.With("tenant_id", 42).WithGroup("http").InfoContext(ctx, GIN,
+		slog.String("path", "/a"), slog.Group("resp", slog.Int("code", 500)), slog.Group("empty"))
+	lg.WithGroup("unused").Warn("no attrs")
+	lg.Error("failed", "err", errors.New("boom"), slog.Group("", slog.Bool("inline", true)))
+
+	entries := r.Entries(RingFilter{})
+	if len(entries) != 3 {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	e := entries[0]
+	want := map[string]interface{}{
+		"request_id": "r1",
+		"tenant_id":  int64(42),
+		"http": map[string]interface{}{
+			"path": "/a",
+			"resp": map[string]interface{}{"code": int64(500)},
+		},
+	}
+	if e.Logger != "api" || e.Prefix != GIN || e.Level != "INFO" || !reflect.DeepEqual(e.Fields, want) {
+		t.Fatalf("unexpected entry %+v", e)
+	}
+	if !strings.HasSuffix(e.Caller, "slog_test.go:20") {
+		t.Fatalf("unexpected caller %q", e.Caller)
+	}
+	if entries[1].Level != "WARN" || len(entries[1].Fields) != 0 {
+		t.Fatalf("empty group kept: %+v", entries[1])
+	}
+	want = map[string]interface{}{"err": "boom", "inline": true}
+	if entries[2].Level != "ERROR" || !reflect.DeepEqual(entries[2].Fields, want) {
+		t.Fatalf("unexpected entry %+v", entries[2])
+	}
+}
+
+func TestSlogHandlerGroupAttrs(t *testing.T) {
+	r := NewRingBuffer(10)
+	lg := slog.New(NewSlogHandler(zap.New(r.Core(zapcore.InfoLevel))))
+
+	ctx := WithFields(context.Background(), zap.String("request_id", "r1"))
+	lg.WithGroup("http").With("method", "GET").WithGroup("resp").InfoContext(ctx, "grouped", "code", 200)
+	lg.WithGroup("http").With("method", "GET").InfoContext(ctx, "no attrs")
+
+	entries := r.Entries(RingFilter{})
+	want := map[string]interface{}{
+		"request_id": "r1",
+		"http": map[string]interface{}{
+			"method": "GET",
+			"resp":   map[string]interface{}{"code": int64(200)},
+		},
+	}
+	if len(entries) != 2 || !reflect.DeepEqual(entries[0].Fields, want) {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	want = map[string]interface{}{
+		"request_id": "r1",
+		"http":       map[string]interface{}{"method": "GET"},
+	}
+	if !reflect.DeepEqual(entries[1].Fields, want) {
+		t.Fatalf("unexpected entry %+v", entries[1])
+	}
+}
+
+func TestSlogHandlerNil(t *testing.T) {
+	defer func(l *zap.Logger) { logger = l }(logger)
+	logger = nil
+	h := NewSlogHandler(nil)
+	if h.Enabled(context.Background(), slog.LevelError) {
+		t.Fatal("handler without logger is enabled")
+	}
+	slog.New(h).Error("discarded")
+}
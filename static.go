@@ -0,0 +1,67 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 21:45
+ * @FilePath: log//static.go
+ */
+
+package log
+
+import (
+	"go.uber.org/zap"
+	"os"
+	"runtime/debug"
+	"sort"
+	"sync"
+)
+
+// buildInfo is the version and VCS revision of the binary, read once
+var buildInfo = sync.OnceValues(func() (version, revision string) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", ""
+	}
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		version = v
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" {
+			revision = s.Value
+		}
+	}
+	return version, revision
+})
+
+// staticFields returns the fields added to every entry, none when Service, Env, Version and Labels are not set
+func (l *Config) staticFields() []zap.Field {
+	if l.Service == "" && l.Env == "" && l.Version == "" && len(l.Labels) == 0 {
+		return nil
+	}
+	version, revision := buildInfo()
+	if l.Version != "" {
+		version = l.Version
+	}
+	fields := make([]zap.Field, 0, 6+len(l.Labels))
+	add := func(key, value string) {
+		if value != "" {
+			fields = append(fields, zap.String(key, value))
+		}
+	}
+	add("service", l.Service)
+	add("env", l.Env)
+	add("version", version)
+	add("revision", revision)
+	host, _ := os.Hostname()
+	add("hostname", host)
+	fields = append(fields, zap.Int("pid", os.Getpid()))
+
+	keys := make([]string, 0, len(l.Labels))
+	for k := range l.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		add(k, l.Labels[k])
+	}
+	return fields
+}
@@ -0,0 +1,41 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStaticFields(t *testing.T) {
+	c := &Config{
+		Level:    "info",
+		Filename: filepath.Join(t.TempDir(), "app.log"),
+		Console:  "fatal",
+		RingSize: 4,
+		Service:  "orders",
+		Env:      "prod",
+		Version:  "v1.2.3",
+		Labels:   map[string]string{"region": "eu-west-1"},
+	}
+	c.NewLogger().Named("api").Info("started")
+	plain := &Config{Level: "info", Filename: c.Filename, Console: "fatal", RingSize: 4}
+	plain.NewLogger().Info("plain")
+	if f := plain.Ring().Entries(RingFilter{})[0].Fields; len(f) != 0 {
+		t.Fatalf("unexpected fields without service %v", f)
+	}
+
+	e := c.Ring().Entries(RingFilter{})[0]
+	host, _ := os.Hostname()
+	for k, want := range map[string]interface{}{
+		"service":  "orders",
+		"env":      "prod",
+		"version":  "v1.2.3",
+		"region":   "eu-west-1",
+		"hostname": host,
+		"pid":      int64(os.Getpid()),
+	} {
+		if e.Fields[k] != want {
+			t.Errorf("%s = %v, want %v", k, e.Fields[k], want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/20 01:10
+ * @FilePath: log//stats.go
+ */
+
+package log
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"sync/atomic"
+	"time"
+)
+
+// RequestStats counts the SQL statements and Elasticsearch calls made for a
+// request. GormLogger, XormLogger and ElasticsearchLog add to the stats found
+// in the context of the query, see ContextWithStats.
+type RequestStats struct {
+	sqlCount, sqlNanos atomic.Int64
+	esCount, esNanos   atomic.Int64
+}
+
+// ctxStatsKey is the context key of the RequestStats
+type ctxStatsKey struct{}
+
+// ContextWithStats returns a copy of ctx carrying new stats
+func ContextWithStats(ctx context.Context) (context.Context, *RequestStats) {
+	s := new(RequestStats)
+	return context.WithValue(ctx, ctxStatsKey{}, s), s
+}
+
+// StatsFromContext returns the stats of the context, nil when there is none
+func StatsFromContext(ctx context.Context) *RequestStats {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(ctxStatsKey{}).(*RequestStats)
+	return s
+}
+
+// AddSQL counts a SQL statement, s may be nil
+func (s *RequestStats) AddSQL(d time.Duration) {
+	if s != nil {
+		s.sqlCount.Add(1)
+		s.sqlNanos.Add(int64(d))
+	}
+}
+
+// AddElastic counts an Elasticsearch call, s may be nil
+func (s *RequestStats) AddElastic(d time.Duration) {
+	if s != nil {
+		s.esCount.Add(1)
+		s.esNanos.Add(int64(d))
+	}
+}
+
+// SQL returns the number of SQL statements and their total time
+func (s *RequestStats) SQL() (int64, time.Duration) {
+	return s.sqlCount.Load(), time.Duration(s.sqlNanos.Load())
+}
+
+// Elastic returns the number of Elasticsearch calls and their total time
+func (s *RequestStats) Elastic() (int64, time.Duration) {
+	return s.esCount.Load(), time.Duration(s.esNanos.Load())
+}
+
+// Fields returns the SQLCount, SQLTime, ESCount and ESTime fields
+func (s *RequestStats) Fields() []zap.Field {
+	sqlCount, sqlTime := s.SQL()
+	esCount, esTime := s.Elastic()
+	return []zap.Field{
+		zap.Int64("SQLCount", sqlCount),
+		zap.String("SQLTime", sqlTime.String()),
+		zap.Int64("ESCount", esCount),
+		zap.String("ESTime", esTime.String()),
+	}
+}
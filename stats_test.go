@@ -0,0 +1,46 @@
+package log_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/restoflife/log"
+	"github.com/restoflife/log/logtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	glog "gorm.io/gorm/logger"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	xlog "xorm.io/xorm/log"
+)
+
+func TestGinDownstreamStats(t *testing.T) {
+	lg, rec := logtest.New(zapcore.DebugLevel)
+	gormLogger := log.NewGormLogger(lg).LogMode(glog.Silent)
+	xormLogger := log.NewXormLogger(lg)
+	esLogger := log.NewElasticLogger(lg, false, false)
+	engine := logtest.NewGin(log.WithConfig(lg, log.ConfigGin{DownstreamStats: true}))
+	engine.GET("/users", func(c *gin.Context) {
+		ctx := c.Request.Context()
+		for i := 0; i < 3; i++ {
+			logtest.TraceGorm(ctx, gormLogger, "SELECT * FROM orders WHERE user_id = 1", 1, 2*time.Millisecond, nil)
+		}
+		lc := xlog.LogContext{Ctx: ctx, SQL: "SELECT 1", ExecuteTime: 4 * time.Millisecond}
+		xormLogger.BeforeSQL(lc)
+		xormLogger.AfterSQL(lc)
+		req := httptest.NewRequest(http.MethodGet, "http://es:9200/users/_search", nil).WithContext(ctx)
+		res := &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: http.NoBody, Request: req}
+		_ = esLogger.LogRoundTrip(req, res, nil, time.Now().Add(-15*time.Millisecond), 15*time.Millisecond)
+	})
+
+	logtest.Get(engine, "/users")
+	e := rec.AssertLogged(t, zapcore.InfoLevel, log.GIN,
+		zap.Int64("SQLCount", 4),
+		zap.Int64("ESCount", 1),
+		zap.String("ESTime", "15ms"),
+	)
+	// The GORM time is measured from the start of the statement
+	if d, err := time.ParseDuration(e.ContextMap()["SQLTime"].(string)); err != nil || d < 10*time.Millisecond {
+		t.Fatalf("unexpected SQLTime %v", e.ContextMap()["SQLTime"])
+	}
+}
@@ -0,0 +1,178 @@
+/*
+ * @Author:   Administrator
+ * @IDE:      GoLand
+ * @Date:     2026/10/19 23:00
+ * @FilePath: log//trace.go
+ */
+
+package log
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strings"
+)
+
+const (
+	// TraceIDField, SpanIDField and ParentSpanIDField are the field names of the span context
+	TraceIDField      = "trace_id"
+	SpanIDField       = "span_id"
+	ParentSpanIDField = "parent_span_id"
+)
+
+// SpanContext is the W3C trace context of a request, IDs are lowercase hex
+type SpanContext struct {
+	// TraceID is the 32 hex digit trace ID
+	TraceID string
+	// SpanID is the 16 hex digit ID of the span
+	SpanID string
+	// ParentSpanID is the span ID of the caller, if any
+	ParentSpanID string
+	// Sampled is the sampled flag of the caller
+	Sampled bool
+	// TraceState is the tracestate header of the caller, passed on as is
+	TraceState string
+}
+
+// ctxSpanKey is the context key of the SpanContext
+type ctxSpanKey struct{}
+
+// ContextWithSpan returns a copy of ctx carrying the span context
+func ContextWithSpan(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, ctxSpanKey{}, sc)
+}
+
+// SpanFromContext returns the span context set by Trace
+func SpanFromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(ctxSpanKey{}).(SpanContext)
+	return sc, ok
+}
+
+// Traceparent returns the traceparent header of the span, to pass to the downstream services
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+// Fields returns the trace_id, span_id and parent_span_id fields
+func (sc SpanContext) Fields() []zap.Field {
+	fields := []zap.Field{zap.String(TraceIDField, sc.TraceID), zap.String(SpanIDField, sc.SpanID)}
+	if sc.ParentSpanID != "" {
+		fields = append(fields, zap.String(ParentSpanIDField, sc.ParentSpanID))
+	}
+	return fields
+}
+
+// ParseTraceparent parses a W3C traceparent header, the span ID is the one of the caller
+func ParseTraceparent(h string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(h), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || !isHex(parts[0]) {
+		return SpanContext{}, false
+	}
+	// Version 00 has exactly four parts, later versions may add more
+	if parts[0] == "00" && len(parts) != 4 {
+		return SpanContext{}, false
+	}
+	if !validID(parts[1], 32) || !validID(parts[2], 16) || len(parts[3]) != 2 || !isHex(parts[3]) {
+		return SpanContext{}, false
+	}
+	flags, _ := hex.DecodeString(parts[3])
+	return SpanContext{TraceID: parts[1], SpanID: parts[2], Sampled: flags[0]&1 == 1}, true
+}
+
+// ParseB3 parses the single b3 header, "{TraceId}-{SpanId}-{SamplingState}-{ParentSpanId}"
+func ParseB3(h string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(h), "-")
+	if len(parts) < 2 {
+		return SpanContext{}, false
+	}
+	sampled := ""
+	if len(parts) > 2 {
+		sampled = parts[2]
+	}
+	return parseB3(parts[0], parts[1], sampled)
+}
+
+// parseB3 parses the B3 IDs, 64 bit trace IDs are left padded
+func parseB3(traceID, spanID, sampled string) (SpanContext, bool) {
+	traceID, spanID = strings.ToLower(traceID), strings.ToLower(spanID)
+	if len(traceID) == 16 {
+		traceID = strings.Repeat("0", 16) + traceID
+	}
+	if !validID(traceID, 32) || !validID(spanID, 16) {
+		return SpanContext{}, false
+	}
+	return SpanContext{TraceID: traceID, SpanID: spanID, Sampled: sampled == "1" || sampled == "d" || sampled == "true"}, true
+}
+
+// validID reports whether the ID is n lowercase hex digits and not all zero
+func validID(id string, n int) bool {
+	return len(id) == n && isHex(id) && strings.Trim(id, "0") != ""
+}
+
+// isHex reports whether s is made of lowercase hex digits
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// newID returns n random bytes in hex, never all zero
+func newID(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	b[n-1] |= 1
+	return hex.EncodeToString(b)
+}
+
+// TraceConfig defines the config for Trace middleware.
+type TraceConfig struct {
+	// B3 also reads the b3 and X-B3-* headers of Zipkin when traceparent is missing
+	B3 bool
+	// ResponseHeader writes the traceparent of the request span in the response
+	ResponseHeader bool
+}
+
+// Trace instances a middleware reading the traceparent header, see TraceWithConfig.
+func Trace(logger *zap.Logger) gin.HandlerFunc {
+	return TraceWithConfig(logger, TraceConfig{B3: true})
+}
+
+// TraceWithConfig instances a middleware reading the W3C trace context of the
+// request, or starting a trace when there is none, without tracing SDK. A span
+// ID is created for the request, the caller's span becomes its parent. The
+// trace_id and span_id fields are added to the access log, the Recovery output,
+// the request logger (see FromGin) and the request context, where the GORM and
+// XORM loggers read them. SpanFromContext returns the span context.
+func TraceWithConfig(logger *zap.Logger, conf TraceConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parent, ok := ParseTraceparent(c.GetHeader("traceparent"))
+		if ok {
+			parent.TraceState = c.GetHeader("tracestate")
+		} else if conf.B3 {
+			if parent, ok = ParseB3(c.GetHeader("b3")); !ok {
+				parent, ok = parseB3(c.GetHeader("X-B3-TraceId"), c.GetHeader("X-B3-SpanId"), c.GetHeader("X-B3-Sampled"))
+			}
+		}
+		sc := SpanContext{TraceID: newID(16), SpanID: newID(8), Sampled: true}
+		if ok {
+			sc.TraceID, sc.ParentSpanID, sc.Sampled, sc.TraceState = parent.TraceID, parent.SpanID, parent.Sampled, parent.TraceState
+		}
+		if conf.ResponseHeader {
+			c.Header("traceparent", sc.Traceparent())
+		}
+		c.Request = c.Request.WithContext(ContextWithSpan(c.Request.Context(), sc))
+		addRequestFields(c, logger, sc.Fields()...)
+		c.Next()
+	}
+}
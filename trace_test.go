@@ -0,0 +1,94 @@
+package log_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/restoflife/log"
+	"github.com/restoflife/log/logtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseTraceContext(t *testing.T) {
+	cases := []struct {
+		h       string
+		b3      bool
+		ok      bool
+		sampled bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, true, true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", false, true, false},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false, true, true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false, false, false},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, false, false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", false, false, false},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false, false, false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false, false, false},
+		{"4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1", true, true, true},
+		{"a3ce929d0e0e4736-00f067aa0ba902b7", true, true, false},
+		{"0", true, false, false},
+	}
+	for _, tc := range cases {
+		parse := log.ParseTraceparent
+		if tc.b3 {
+			parse = log.ParseB3
+		}
+		sc, ok := parse(tc.h)
+		if ok != tc.ok || sc.Sampled != tc.sampled {
+			t.Errorf("%q: got %+v %v", tc.h, sc, ok)
+		}
+		if ok && sc.SpanID != "00f067aa0ba902b7" {
+			t.Errorf("%q: span %q", tc.h, sc.SpanID)
+		}
+	}
+	sc := log.SpanContext{TraceID: "0af7651916cd43dd8448eb211c80319c", SpanID: "b7ad6b7169203331", Sampled: true}
+	if got, ok := log.ParseTraceparent(sc.Traceparent()); !ok || got.TraceID != sc.TraceID || got.SpanID != sc.SpanID {
+		t.Fatalf("round trip %+v %v", got, ok)
+	}
+}
+
+func TestTrace(t *testing.T) {
+	lg, rec := logtest.New(zapcore.DebugLevel)
+	gormLogger := log.NewGormLogger(lg)
+	engine := logtest.NewGin(log.RequestID(lg), log.TraceWithConfig(lg, log.TraceConfig{B3: true, ResponseHeader: true}), log.GinLogger(lg))
+	engine.GET("/users", func(c *gin.Context) {
+		logtest.TraceGorm(c.Request.Context(), gormLogger, "SELECT * FROM users", 2, time.Millisecond, nil)
+		log.FromGin(c).Info("handler")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	res := logtest.ServeGin(engine, req)
+	sc, ok := log.ParseTraceparent(res.Header().Get("traceparent"))
+	if !ok || sc.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID == "00f067aa0ba902b7" || !sc.Sampled {
+		t.Fatalf("unexpected traceparent %q", res.Header().Get("traceparent"))
+	}
+	fields := []zap.Field{
+		zap.String(log.TraceIDField, sc.TraceID),
+		zap.String(log.SpanIDField, sc.SpanID),
+		zap.String(log.ParentSpanIDField, "00f067aa0ba902b7"),
+	}
+	e := rec.AssertLogged(t, zapcore.InfoLevel, log.GORM, fields...)
+	logtest.AssertHasField(t, e, log.RequestIDField)
+	rec.AssertLogged(t, zapcore.InfoLevel, "handler", fields...)
+	rec.AssertLogged(t, zapcore.InfoLevel, log.GIN, fields...)
+
+	// B3 headers, 64 bit trace IDs are padded
+	req = httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("X-B3-TraceId", "a3ce929d0e0e4736")
+	req.Header.Set("X-B3-SpanId", "00f067aa0ba902b7")
+	logtest.ServeGin(engine, req)
+	rec.AssertLogged(t, zapcore.InfoLevel, "handler", zap.String(log.TraceIDField, "0000000000000000a3ce929d0e0e4736"))
+
+	// A trace is started without headers
+	rec.TakeAll()
+	logtest.ServeGin(engine, httptest.NewRequest(http.MethodGet, "/users", nil))
+	e = rec.AssertLogged(t, zapcore.InfoLevel, "handler")
+	logtest.AssertHasField(t, e, log.TraceIDField)
+	if _, ok := e.ContextMap()[log.ParentSpanIDField]; ok {
+		t.Fatalf("unexpected parent span %v", e.ContextMap())
+	}
+}
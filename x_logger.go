@@ -41,17 +41,18 @@ func (o *XormLogger) BeforeSQL(_ log.LogContext) {}
 
 // AfterSQL Function to log SQL statements after they have been executed
 func (o *XormLogger) AfterSQL(ctx log.LogContext) {
+	StatsFromContext(ctx.Ctx).AddSQL(ctx.ExecuteTime)
 	sql, _ := builder.ConvertToBoundSQL(ctx.SQL, ctx.Args)
 	o.logLvl = zapcore.InfoLevel
 	if ctx.Err != nil {
 		o.logLvl = zapcore.ErrorLevel
 	}
 	if o.logger.Core().Enabled(o.logLvl) {
-		o.logger.Check(o.logLvl, SQL).Write(
+		o.logger.Check(o.logLvl, SQL).Write(append([]zap.Field{
 			zap.String("SQL", sql),
 			zap.String("Latency", ctx.ExecuteTime.String()),
 			zap.Error(ctx.Err),
-		)
+		}, ContextFields(ctx.Ctx)...)...)
 	}
 }
 